@@ -9,20 +9,34 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"specprint/pkg/claude"
+	"specprint/pkg/forge"
+	"specprint/pkg/llm"
 
 	"os/exec"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/sashabaranov/go-openai"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
-	ctx context.Context
+	ctx        context.Context
+	tasks      *taskStore
+	settings   *settingsStore
+	sessions   *sessionStore
+	prdHistory *prdHistoryStore
+	worktreeMu sync.Mutex // Serializes worktree setup in the workspace repositories
+
+	runnersMu     sync.Mutex
+	activeRunners map[string]bool // Workspaces whose tasks are being run by RunWorkspaceTasks
+
+	cancelsMu   sync.Mutex
+	taskCancels map[string]context.CancelFunc // Cancels the Claude run of a task, keyed by taskRunKey
 }
 
 // CloneResult represents the result of a repository clone operation
@@ -37,31 +51,62 @@ type PRDResult struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 	Path    string `json:"path,omitempty"`
+	Version int    `json:"version,omitempty"` // The PRD version the save or restore was recorded as
 }
 
 // ClaudeSessionResult represents the result of Claude operations
 type ClaudeSessionResult struct {
-	Success      bool     `json:"success"`
-	Message      string   `json:"message"`
-	Response     string   `json:"response,omitempty"`
-	FilesChanged []string `json:"filesChanged,omitempty"`
+	Success         bool                    `json:"success"`
+	Message         string                  `json:"message"`
+	Response        string                  `json:"response,omitempty"`
+	FilesChanged    []string                `json:"filesChanged,omitempty"`
+	Cancelled       bool                    `json:"cancelled,omitempty"`
+	RunConfig       *claude.RunConfig       `json:"runConfig,omitempty"` // The effective config of the session
+	Summary         string                  `json:"summary,omitempty"`   // The final result Claude reported
+	Usage           *claude.Usage           `json:"usage,omitempty"`
+	ToolInvocations []claude.ToolInvocation `json:"toolInvocations,omitempty"`
+	Verification    *VerificationResult     `json:"verification,omitempty"` // Checks run on the changes before they were committed
+	Pushed          bool                    `json:"pushed,omitempty"`       // The changes were committed and pushed, even if verification failed
 }
 
 // Task represents a single implementation task
 type Task struct {
-	ID           int    `json:"id"`
-	Title        string `json:"title"`
-	Description  string `json:"description"`
-	Dependencies []int  `json:"dependencies"`
-	Priority     string `json:"priority"` // "high", "medium", "low"
-	Estimate     string `json:"estimate"` // e.g., "2h", "1d", "3d"
+	ID            int               `json:"id"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	Dependencies  []int             `json:"dependencies"`
+	Priority      string            `json:"priority"` // "high", "medium", "low"
+	Estimate      string            `json:"estimate"` // e.g., "2h", "1d", "3d"
+	Status        TaskStatus        `json:"status,omitempty"`
+	BranchName    string            `json:"branchName,omitempty"`
+	SessionID     string            `json:"sessionId,omitempty"`
+	WorktreePath  string            `json:"worktreePath,omitempty"`
+	LastRunAt     *time.Time        `json:"lastRunAt,omitempty"`
+	LastError     string            `json:"lastError,omitempty"`
+	RunConfig     *claude.RunConfig `json:"runConfig,omitempty"`     // Overrides the workspace run config for this task
+	LastRunConfig *claude.RunConfig `json:"lastRunConfig,omitempty"` // The effective config of the last run
+	LastSummary   string            `json:"lastSummary,omitempty"`   // What Claude reported doing in the last run
+	LastUsage     *claude.Usage     `json:"lastUsage,omitempty"`     // Tokens and cost of the last run
+	PRDVersion    int               `json:"prdVersion,omitempty"`    // The PRD version the task was generated from
+	Spec          string            `json:"spec,omitempty"`          // The spec document the task belongs to; empty for PRD.md
+	// LastVerification is the outcome of the checks run on the changes of the last run
+	LastVerification *VerificationResult `json:"lastVerification,omitempty"`
+	PullRequest      *forge.PullRequest  `json:"pullRequest,omitempty"` // The pull request opened from the task's branch
+	// AcceptanceCriteria are checkable conditions that must hold for the task to be done
+	AcceptanceCriteria []string `json:"acceptanceCriteria,omitempty"`
+	Files              []string `json:"files,omitempty"`       // Files the task is likely to touch
+	TestPlan           string   `json:"testPlan,omitempty"`    // How to verify the task once it is implemented
+	IssueNumber        int      `json:"issueNumber,omitempty"` // The tracker issue the task was imported from or exported to
+	IssueURL           string   `json:"issueUrl,omitempty"`
 }
 
 // TaskGenerationResult represents the result of task generation
 type TaskGenerationResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	Tasks   []Task `json:"tasks,omitempty"` // Changed from Epics []Epic
+	Success        bool             `json:"success"`
+	Message        string           `json:"message"`
+	Tasks          []Task           `json:"tasks,omitempty"`          // Changed from Epics []Epic
+	Diagnostics    []TaskDiagnostic `json:"diagnostics,omitempty"`    // Problems found in the dependency graph
+	RepairAttempts int              `json:"repairAttempts,omitempty"` // How often the model was asked to fix its output
 }
 
 // Workspace represents a cloned repository workspace
@@ -84,13 +129,22 @@ type WorkspacesResult struct {
 
 // TaskExecutionResult represents the result of executing a task with Git branching and Claude
 type TaskExecutionResult struct {
-	Success      bool     `json:"success"`
-	Message      string   `json:"message"`
-	BranchName   string   `json:"branchName,omitempty"`
-	FilesChanged []string `json:"filesChanged,omitempty"`
-	ClaudeOutput string   `json:"claudeOutput,omitempty"`
-	SessionID    string   `json:"sessionId,omitempty"`
-	WorktreePath string   `json:"worktreePath,omitempty"`
+	Success         bool                    `json:"success"`
+	Message         string                  `json:"message"`
+	BranchName      string                  `json:"branchName,omitempty"`
+	FilesChanged    []string                `json:"filesChanged,omitempty"`
+	ClaudeOutput    string                  `json:"claudeOutput,omitempty"`
+	SessionID       string                  `json:"sessionId,omitempty"`
+	WorktreePath    string                  `json:"worktreePath,omitempty"`
+	BlockedBy       []TaskBlocker           `json:"blockedBy,omitempty"`     // Unfinished dependencies that kept the task from running
+	Cancelled       bool                    `json:"cancelled,omitempty"`     // The run was stopped with CancelTask
+	RunConfig       *claude.RunConfig       `json:"runConfig,omitempty"`     // The effective config Claude ran with
+	ClaudeSummary   string                  `json:"claudeSummary,omitempty"` // The final result Claude reported; ClaudeOutput has the full transcript
+	Usage           *claude.Usage           `json:"usage,omitempty"`
+	ToolInvocations []claude.ToolInvocation `json:"toolInvocations,omitempty"`
+	Verification    *VerificationResult     `json:"verification,omitempty"` // Checks run on the changes before they were committed
+	Pushed          bool                    `json:"pushed,omitempty"`       // The changes were committed and pushed to BranchName, even if verification failed
+	PullRequest     *forge.PullRequest      `json:"pullRequest,omitempty"`  // The task's pull request, if one was opened
 }
 
 // BranchInfo represents information about a Git branch
@@ -112,7 +166,12 @@ type BranchListResult struct {
 func NewApp() *App {
 	// Load environment variables from .env file if it exists
 	loadEnvFile()
-	return &App{}
+	return &App{
+		tasks:      &taskStore{},
+		settings:   &settingsStore{},
+		sessions:   &sessionStore{},
+		prdHistory: &prdHistoryStore{},
+	}
 }
 
 // loadEnvFile loads environment variables from .env file
@@ -153,6 +212,15 @@ func loadEnvFile() {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	go a.pollPullRequests(ctx)
+}
+
+// emitEvent sends an event to the frontend. It is a no-op until the app has started, e.g. in tests.
+func (a *App) emitEvent(eventName string, data ...interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, eventName, data...)
 }
 
 // Greet returns a greeting for the given name
@@ -160,8 +228,32 @@ func (a *App) Greet(name string) string {
 	return fmt.Sprintf("Hello %s, It's show time!", name)
 }
 
-// GenerateTasks uses OpenAI to parse PRD content and generate structured tasks
+// TaskGenerationOptions controls how task generation deals with unusable model output
+type TaskGenerationOptions struct {
+	// RepairAttempts is how many times the model is asked to fix unparseable output or an invalid
+	// graph; 0 disables repair
+	RepairAttempts int `json:"repairAttempts"`
+	// MaxChunkChars is the size above which a PRD is split by markdown section and its parts are
+	// generated separately; 0 uses the default
+	MaxChunkChars int `json:"maxChunkChars,omitempty"`
+	// Repository summarizes the codebase the PRD will be implemented in, so tasks reference real
+	// files and skip work that is already done. It is filled in when generating for a workspace.
+	Repository string `json:"-"`
+}
+
+// GenerateTasks uses the configured LLM provider to parse PRD content and generate structured tasks
 func (a *App) GenerateTasks(prdContent string) TaskGenerationResult {
+	return a.GenerateTasksWithOptions(prdContent, TaskGenerationOptions{RepairAttempts: 1})
+}
+
+// GenerateTasksWithOptions generates tasks like GenerateTasks, with control over repair and chunking
+func (a *App) GenerateTasksWithOptions(prdContent string, options TaskGenerationOptions) TaskGenerationResult {
+	return a.generateTasksForWorkspace("", prdContent, options)
+}
+
+// generateTasksForWorkspace generates tasks with the provider selected for a workspace. An
+// empty workspace name uses the app-wide provider.
+func (a *App) generateTasksForWorkspace(workspaceName, prdContent string, options TaskGenerationOptions) TaskGenerationResult {
 	// Validate input
 	if strings.TrimSpace(prdContent) == "" {
 		return TaskGenerationResult{
@@ -170,18 +262,33 @@ func (a *App) GenerateTasks(prdContent string) TaskGenerationResult {
 		}
 	}
 
-	// Get OpenAI API key from environment
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
+	generator, err := a.taskGenerator(workspaceName)
+	if err != nil {
 		return TaskGenerationResult{
 			Success: false,
-			Message: "OPENAI_API_KEY environment variable is not set",
+			Message: err.Error(),
 		}
 	}
 
-	// Create OpenAI client
-	client := openai.NewClient(apiKey)
+	// Show the model the workspace's code; without it, generation works from the PRD alone
+	if workspaceName != "" && options.Repository == "" {
+		options.Repository = a.workspaceRepositorySummary(workspaceName)
+	}
 
+	return generateTasksInChunks(generator, prdContent, options, func(progress TaskGenerationProgress) {
+		progress.Workspace = workspaceName
+		a.emitEvent(TaskGenerationProgressEvent, progress)
+	})
+}
+
+// generateTasks asks the generator for the task list of a whole PRD and validates it
+func generateTasks(generator llm.TaskGenerator, prdContent string, options TaskGenerationOptions) TaskGenerationResult {
+	return generateTaskList(generator, fmt.Sprintf("Please analyze this PRD and generate implementation tasks:\n\n%s%s", prdContent, repositoryPrompt(options.Repository)), options)
+}
+
+// generateTaskList sends the task generation prompt with the given user message and validates
+// the returned task list
+func generateTaskList(generator llm.TaskGenerator, userMessage string, options TaskGenerationOptions) TaskGenerationResult {
 	// Construct the system prompt
 	systemPrompt := `You are an expert project manager and software architect. Your task is to analyze a Product Requirements Document (PRD) and generate a flat list of actionable tasks with proper dependencies.
 
@@ -195,6 +302,9 @@ For each task, provide:
 - dependencies: Array of task IDs that must be completed first (use [] if none)
 - priority: "high", "medium", or "low"
 - estimate: Time estimate like "2h", "1d", "3d"
+- acceptanceCriteria: 2-5 concrete, checkable conditions that must hold when the task is done
+- files: Paths of files the task is likely to create or change (use [] if unknown)
+- testPlan: How to verify the task, e.g. which tests to add or run and what to check manually
 
 DEPENDENCY RULES:
 1. **Setup Dependencies**: Infrastructure and setup tasks should have no dependencies
@@ -219,138 +329,177 @@ TASK GENERATION RULES:
 6. Ensure tasks are independent but properly sequenced via dependencies
 7. Create logical task groups that can be worked on in parallel when possible
 
-Return ONLY a valid JSON array of tasks. Do not include any other text or formatting.
+Return ONLY a JSON object with a "tasks" array. Do not include any other text or formatting.
 
 Example format:
-[
-  {
-    "id": 1,
-    "title": "Set up project repository",
-    "description": "Initialize Git repository and basic structure",
-    "dependencies": [],
-    "priority": "high",
-    "estimate": "1h"
-  },
-  {
-    "id": 2,
-    "title": "Design database schema",
-    "description": "Create database tables and relationships",
-    "dependencies": [],
-    "priority": "high",
-    "estimate": "4h"
-  },
-  {
-    "id": 3,
-    "title": "Implement user authentication",
-    "description": "Create login/register API endpoints",
-    "dependencies": [1, 2],
-    "priority": "high",
-    "estimate": "8h"
-  }
-]`
-
-	// Create the chat completion request
-	req := openai.ChatCompletionRequest{
-		Model: openai.GPT4oMini,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: systemPrompt,
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: fmt.Sprintf("Please analyze this PRD and generate implementation tasks:\n\n%s", prdContent),
-			},
+{
+  "tasks": [
+    {
+      "id": 1,
+      "title": "Set up project repository",
+      "description": "Initialize Git repository and basic structure",
+      "dependencies": [],
+      "priority": "high",
+      "estimate": "1h",
+      "acceptanceCriteria": ["Repository has a README and .gitignore", "Project builds from a clean checkout"],
+      "files": ["README.md", ".gitignore"],
+      "testPlan": "Clone the repository and run the build"
+    },
+    {
+      "id": 2,
+      "title": "Design database schema",
+      "description": "Create database tables and relationships",
+      "dependencies": [],
+      "priority": "high",
+      "estimate": "4h",
+      "acceptanceCriteria": ["Users and sessions tables exist with foreign keys", "Migrations apply and roll back cleanly"],
+      "files": ["db/migrations/001_init.sql"],
+      "testPlan": "Apply and roll back the migration against an empty database"
+    },
+    {
+      "id": 3,
+      "title": "Implement user authentication",
+      "description": "Create login/register API endpoints",
+      "dependencies": [1, 2],
+      "priority": "high",
+      "estimate": "8h",
+      "acceptanceCriteria": ["POST /register creates a user with a hashed password", "POST /login returns a session token for valid credentials and 401 otherwise"],
+      "files": ["api/auth.go", "api/auth_test.go"],
+      "testPlan": "Add handler tests for successful and failed login and registration"
+    }
+  ]
+}`
+
+	messages := []llm.Message{
+		{
+			Role:    llm.RoleSystem,
+			Content: systemPrompt,
+		},
+		{
+			Role:    llm.RoleUser,
+			Content: userMessage,
 		},
-		MaxTokens:   2000,
-		Temperature: 0.1, // Low temperature for consistent, structured output
 	}
 
-	// Make the API call
-	resp, err := client.CreateChatCompletion(context.Background(), req)
-	if err != nil {
-		return TaskGenerationResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to call OpenAI API: %v", err),
+	var tasks []Task
+	var diagnostics []TaskDiagnostic
+	attempt := 0
+	for {
+		// Make the API call
+		responseContent, err := generator.Complete(context.Background(), llm.Request{
+			Messages:    messages,
+			MaxTokens:   taskGenerationMaxTokens,
+			Temperature: 0.1, // Low temperature for consistent, structured output
+			Schema:      taskListSchema,
+		})
+		if err != nil {
+			return TaskGenerationResult{
+				Success: false,
+				Message: fmt.Sprintf("Failed to call %s API: %v", generator.Name(), err),
+			}
 		}
-	}
 
-	if len(resp.Choices) == 0 {
-		return TaskGenerationResult{
-			Success: false,
-			Message: "No response received from OpenAI",
+		// Parse the JSON response, tolerating code fences and surrounding prose
+		var repairPrompt string
+		tasks, err = parseGeneratedTasks(responseContent)
+		if err != nil {
+			if attempt >= options.RepairAttempts {
+				return TaskGenerationResult{
+					Success:        false,
+					Message:        fmt.Sprintf("Failed to parse JSON response: %v. Response was: %s", err, responseContent),
+					RepairAttempts: attempt,
+				}
+			}
+			repairPrompt = fmt.Sprintf("Your response could not be parsed: %v\n\nReturn the complete task list again. Return ONLY a JSON object with a \"tasks\" array, without code fences or any other text.", err)
+		} else if err := validateGeneratedTasks(tasks); err != nil {
+			// Validate the parsed tasks
+			if attempt >= options.RepairAttempts {
+				return TaskGenerationResult{
+					Success:        false,
+					Message:        err.Error(),
+					RepairAttempts: attempt,
+				}
+			}
+			repairPrompt = fmt.Sprintf("The task list is incomplete: %v\n\nReturn the complete corrected task list. Every task needs an id, title, description, dependencies, priority and estimate. Return ONLY the JSON object.", err)
+		} else {
+			// Validate the dependency graph and ask the model to repair it if allowed
+			diagnostics = validateTaskGraph(tasks)
+			if len(diagnostics) == 0 || attempt >= options.RepairAttempts {
+				break
+			}
+			repairPrompt = fmt.Sprintf("The task list has these dependency problems:\n%s\n\nFix them and return the complete corrected task list. Task IDs must be unique, every dependency must refer to an existing task other than the task itself, and dependencies must not form cycles. Return ONLY the JSON object.", formatDiagnostics(diagnostics))
 		}
-	}
 
-	// Get the response content
-	responseContent := resp.Choices[0].Message.Content
+		attempt++
+		messages = append(messages,
+			llm.Message{
+				Role:    llm.RoleAssistant,
+				Content: responseContent,
+			},
+			llm.Message{
+				Role:    llm.RoleUser,
+				Content: repairPrompt,
+			},
+		)
+	}
 
-	// Parse the JSON response
-	var tasks []Task // Changed from var epics []Epic
-	err = json.Unmarshal([]byte(responseContent), &tasks)
-	if err != nil {
+	if len(diagnostics) > 0 {
 		return TaskGenerationResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to parse JSON response: %v. Response was: %s", err, responseContent),
+			Success:        false,
+			Message:        fmt.Sprintf("Generated tasks have an invalid dependency graph:\n%s", formatDiagnostics(diagnostics)),
+			Tasks:          tasks,
+			Diagnostics:    diagnostics,
+			RepairAttempts: attempt,
 		}
 	}
 
-	// Validate the parsed epics
+	return TaskGenerationResult{
+		Success:        true,
+		Message:        fmt.Sprintf("Successfully generated %d tasks from PRD", len(tasks)),
+		Tasks:          tasks, // Changed from Epics: epics
+		RepairAttempts: attempt,
+	}
+}
+
+// validateGeneratedTasks checks that every generated task has all required fields
+func validateGeneratedTasks(tasks []Task) error {
 	if len(tasks) == 0 {
-		return TaskGenerationResult{
-			Success: false,
-			Message: "No tasks were generated from the PRD",
-		}
+		return fmt.Errorf("No tasks were generated from the PRD")
 	}
 
 	for i, task := range tasks {
 		if task.ID <= 0 {
-			return TaskGenerationResult{
-				Success: false,
-				Message: fmt.Sprintf("Task %d has invalid ID: %d", i+1, task.ID),
-			}
+			return fmt.Errorf("Task %d has invalid ID: %d", i+1, task.ID)
 		}
 		if strings.TrimSpace(task.Title) == "" {
-			return TaskGenerationResult{
-				Success: false,
-				Message: fmt.Sprintf("Task %d has empty title", task.ID),
-			}
+			return fmt.Errorf("Task %d has empty title", task.ID)
 		}
 		if strings.TrimSpace(task.Description) == "" {
-			return TaskGenerationResult{
-				Success: false,
-				Message: fmt.Sprintf("Task %d has empty description", task.ID),
-			}
+			return fmt.Errorf("Task %d has empty description", task.ID)
 		}
 		if strings.TrimSpace(task.Priority) == "" {
-			return TaskGenerationResult{
-				Success: false,
-				Message: fmt.Sprintf("Task %d has empty priority", task.ID),
-			}
+			return fmt.Errorf("Task %d has empty priority", task.ID)
 		}
 		if strings.TrimSpace(task.Estimate) == "" {
-			return TaskGenerationResult{
-				Success: false,
-				Message: fmt.Sprintf("Task %d has empty estimate", task.ID),
-			}
+			return fmt.Errorf("Task %d has empty estimate", task.ID)
 		}
 		if task.Dependencies == nil {
-			return TaskGenerationResult{
-				Success: false,
-				Message: fmt.Sprintf("Task %d has nil dependencies", task.ID),
-			}
+			return fmt.Errorf("Task %d has nil dependencies", task.ID)
 		}
 	}
 
-	return TaskGenerationResult{
-		Success: true,
-		Message: fmt.Sprintf("Successfully generated %d tasks from PRD", len(tasks)),
-		Tasks:   tasks, // Changed from Epics: epics
-	}
+	return nil
 }
 
 // GenerateTasksFromWorkspacePRD generates tasks from a specific workspace's PRD file
 func (a *App) GenerateTasksFromWorkspacePRD(workspaceName string) TaskGenerationResult {
+	return a.GenerateTasksFromWorkspaceSpec(workspaceName, DefaultSpecID)
+}
+
+// GenerateTasksFromWorkspaceSpec generates tasks from one spec document of a workspace. The
+// generated tasks replace the tasks previously generated from that spec; tasks of other specs
+// stay on the board.
+func (a *App) GenerateTasksFromWorkspaceSpec(workspaceName, specID string) TaskGenerationResult {
 	// Validate workspace name
 	if strings.TrimSpace(workspaceName) == "" {
 		return TaskGenerationResult{
@@ -359,6 +508,13 @@ func (a *App) GenerateTasksFromWorkspacePRD(workspaceName string) TaskGeneration
 		}
 	}
 
+	if err := validateSpecID(specID); err != nil {
+		return TaskGenerationResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
 	// Get workspaces
 	workspacesResult := a.GetWorkspaces()
 	if !workspacesResult.Success {
@@ -385,7 +541,7 @@ func (a *App) GenerateTasksFromWorkspacePRD(workspaceName string) TaskGeneration
 	}
 
 	// Check if PRD exists
-	if !targetWorkspace.HasPRD {
+	if specID == DefaultSpecID && !targetWorkspace.HasPRD {
 		return TaskGenerationResult{
 			Success: false,
 			Message: fmt.Sprintf("Workspace '%s' does not have a PRD file", workspaceName),
@@ -393,7 +549,13 @@ func (a *App) GenerateTasksFromWorkspacePRD(workspaceName string) TaskGeneration
 	}
 
 	// Read PRD content
-	prdContent, err := os.ReadFile(targetWorkspace.PRDPath)
+	prdContent, err := os.ReadFile(specPath(targetWorkspace.Path, specID))
+	if os.IsNotExist(err) {
+		return TaskGenerationResult{
+			Success: false,
+			Message: fmt.Sprintf("Workspace '%s' does not have %s", workspaceName, specLabel(specID)),
+		}
+	}
 	if err != nil {
 		return TaskGenerationResult{
 			Success: false,
@@ -401,11 +563,64 @@ func (a *App) GenerateTasksFromWorkspacePRD(workspaceName string) TaskGeneration
 		}
 	}
 
-	// Generate tasks using the PRD content
-	return a.GenerateTasks(string(prdContent)) // Now returns tasks
+	// Replacing the spec's tasks is refused while one runs; check before paying for generation
+	board, err := a.tasks.Load(workspaceName)
+	if err == nil {
+		err = board.checkSpecIdle(specID)
+	}
+	if err != nil {
+		return TaskGenerationResult{
+			Success: false,
+			Message: fmt.Sprintf("Cannot regenerate tasks: %v", err),
+		}
+	}
+
+	// Generate tasks using the PRD content and the workspace's provider
+	result := a.generateTasksForWorkspace(workspaceName, string(prdContent), TaskGenerationOptions{RepairAttempts: 1})
+	if !result.Success {
+		return result
+	}
+
+	// Link the tasks to the PRD version they came from; history is best effort here
+	prdVersion, _, err := a.recordCurrentSpec(targetWorkspace, specID)
+	if err != nil {
+		prdVersion = 0
+	}
+
+	// Persist the generated tasks so the board survives restarts and is visible to the backend,
+	// along with the PRD they came from so later PRD changes can be applied incrementally
+	for i := range result.Tasks {
+		result.Tasks[i].Status = TaskStatusTodo
+		result.Tasks[i].Spec = specID
+		result.Tasks[i].PRDVersion = prdVersion
+	}
+	if err := normalizeTaskList(result.Tasks); err != nil {
+		return TaskGenerationResult{
+			Success: false,
+			Message: fmt.Sprintf("Generated %d tasks but failed to save them: %v", len(result.Tasks), err),
+		}
+	}
+	_, err = a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		stored, err := board.replaceSpecTasks(specID, result.Tasks)
+		if err != nil {
+			return err
+		}
+		result.Tasks = stored
+		board.setSource(specID, string(prdContent), prdVersion)
+		return nil
+	})
+	if err != nil {
+		return TaskGenerationResult{
+			Success: false,
+			Message: fmt.Sprintf("Generated %d tasks but failed to save them: %v", len(result.Tasks), err),
+		}
+	}
+
+	return result
 }
 
-// GetWorkspaces returns all available workspaces
+// GetWorkspaces returns all available workspaces. It also cleans up orphaned worktrees and
+// records newly found workspaces in workspaces.json.
 func (a *App) GetWorkspaces() WorkspacesResult {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -415,6 +630,45 @@ func (a *App) GetWorkspaces() WorkspacesResult {
 		}
 	}
 
+	// Clean up any orphaned worktree directories first
+	baseDir := filepath.Join(homeDir, ".aicodingtool", "repos")
+	if repos, err := os.ReadDir(baseDir); err == nil {
+		for _, repo := range repos {
+			if repo.IsDir() && a.isWorktreeDirectory(repo.Name()) {
+				// This is a worktree directory - check if it should be cleaned up
+				repoPath := filepath.Join(baseDir, repo.Name())
+				a.checkAndCleanupOrphanedWorktree(repoPath, repo.Name())
+			}
+		}
+	}
+
+	workspaces, err := a.listWorkspaces()
+	if err != nil {
+		return WorkspacesResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to list workspaces: %v", err),
+		}
+	}
+
+	// Save updated workspaces
+	a.saveWorkspaces(workspaces)
+
+	return WorkspacesResult{
+		Success:    true,
+		Message:    fmt.Sprintf("Found %d workspaces", len(workspaces)),
+		Workspaces: workspaces,
+	}
+}
+
+// listWorkspaces returns the workspaces recorded in workspaces.json, updated from and extended
+// with the repositories on disk. Unlike GetWorkspaces it changes nothing, so it is safe to call
+// from task bindings and background goroutines.
+func (a *App) listWorkspaces() ([]Workspace, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
 	baseDir := filepath.Join(homeDir, ".aicodingtool", "repos")
 	workspacesFile := filepath.Join(homeDir, ".aicodingtool", "workspaces.json")
 
@@ -431,16 +685,6 @@ func (a *App) GetWorkspaces() WorkspacesResult {
 	if _, err := os.Stat(baseDir); err == nil {
 		repos, err := os.ReadDir(baseDir)
 		if err == nil {
-			// First pass: clean up any orphaned worktree directories
-			for _, repo := range repos {
-				if repo.IsDir() && a.isWorktreeDirectory(repo.Name()) {
-					// This is a worktree directory - check if it should be cleaned up
-					repoPath := filepath.Join(baseDir, repo.Name())
-					a.checkAndCleanupOrphanedWorktree(repoPath, repo.Name())
-				}
-			}
-
-			// Second pass: process actual workspaces
 			for _, repo := range repos {
 				if repo.IsDir() {
 					repoPath := filepath.Join(baseDir, repo.Name())
@@ -485,17 +729,8 @@ func (a *App) GetWorkspaces() WorkspacesResult {
 		}
 	}
 
-	// Remove duplicates before saving
-	workspaces = a.deduplicateWorkspaces(workspaces)
-
-	// Save updated workspaces
-	a.saveWorkspaces(workspaces)
-
-	return WorkspacesResult{
-		Success:    true,
-		Message:    fmt.Sprintf("Found %d workspaces", len(workspaces)),
-		Workspaces: workspaces,
-	}
+	// Remove duplicates
+	return a.deduplicateWorkspaces(workspaces), nil
 }
 
 // SaveWorkspacePRD saves PRD content to a specific workspace
@@ -540,6 +775,14 @@ func (a *App) SaveWorkspacePRD(workspaceName, prdContent string) PRDResult {
 		}
 	}
 
+	// Keep the PRD being replaced, in case it was edited outside the app
+	if _, _, err := a.recordCurrentSpec(targetWorkspace, DefaultSpecID); err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to record the current PRD: %v", err),
+		}
+	}
+
 	// Create PRD.md file in the workspace
 	prdFilePath := filepath.Join(targetWorkspace.Path, "PRD.md")
 
@@ -564,10 +807,21 @@ func (a *App) SaveWorkspacePRD(workspaceName, prdContent string) PRDResult {
 	// Save updated workspaces
 	a.saveWorkspaces(workspacesResult.Workspaces)
 
+	// Snapshot the new PRD so earlier requirements can be compared and restored
+	version, err := a.prdHistory.Record(targetWorkspace.Path, DefaultSpecID, prdWithTimestamp, PRDSourceSaved, 0)
+	if err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("PRD saved but failed to record its version: %v", err),
+			Path:    prdFilePath,
+		}
+	}
+
 	return PRDResult{
 		Success: true,
 		Message: fmt.Sprintf("PRD saved successfully to workspace: %s", workspaceName),
 		Path:    prdFilePath,
+		Version: version.Version,
 	}
 }
 
@@ -742,6 +996,17 @@ func (a *App) DeleteWorkspace(workspaceName string, deleteFiles bool) DeleteWork
 	// Clean up any active worktrees for this workspace
 	a.cleanupAllWorktrees(targetWorkspace.Path, workspaceName)
 
+	// Drop the stored task board along with the workspace
+	if err := a.tasks.Delete(workspaceName); err != nil {
+		fmt.Printf("Warning: Failed to delete task board for workspace %s: %v\n", workspaceName, err)
+	}
+	if err := a.settings.DeleteWorkspace(workspaceName); err != nil {
+		fmt.Printf("Warning: Failed to delete settings for workspace %s: %v\n", workspaceName, err)
+	}
+	if err := a.sessions.DeleteWorkspace(workspaceName); err != nil {
+		fmt.Printf("Warning: Failed to delete session transcripts for workspace %s: %v\n", workspaceName, err)
+	}
+
 	// Remove workspace from the list
 	updatedWorkspaces := make([]Workspace, 0, len(workspacesResult.Workspaces)-1)
 	for i, workspace := range workspacesResult.Workspaces {
@@ -1241,6 +1506,11 @@ func (a *App) GetWorkspaceBranches(workspaceName string) BranchListResult {
 
 // RunTask executes a task by creating a Git branch and running Claude Code
 func (a *App) RunTask(workspaceName string, taskID int, taskTitle, taskDescription, baseBranch string) TaskExecutionResult {
+	return a.RunTaskWithOptions(workspaceName, taskID, taskTitle, taskDescription, baseBranch, TaskRunOptions{})
+}
+
+// RunTaskWithOptions executes a task like RunTask, with control over how unfinished dependencies are handled
+func (a *App) RunTaskWithOptions(workspaceName string, taskID int, taskTitle, taskDescription, baseBranch string, options TaskRunOptions) TaskExecutionResult {
 	// Validate input parameters
 	if strings.TrimSpace(workspaceName) == "" {
 		return TaskExecutionResult{
@@ -1270,30 +1540,113 @@ func (a *App) RunTask(workspaceName string, taskID int, taskTitle, taskDescripti
 		}
 	}
 
-	// Get workspaces to find the target workspace
-	workspacesResult := a.GetWorkspaces()
-	if !workspacesResult.Success {
+	// Find the specified workspace
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
 		return TaskExecutionResult{
 			Success: false,
-			Message: workspacesResult.Message,
+			Message: err.Error(),
 		}
 	}
 
-	// Find the specified workspace
-	var targetWorkspace *Workspace
-	for i := range workspacesResult.Workspaces {
-		if workspacesResult.Workspaces[i].Name == workspaceName {
-			targetWorkspace = &workspacesResult.Workspaces[i]
-			break
+	return a.executeTask(targetWorkspace, taskID, taskTitle, taskDescription, baseBranch, options)
+}
+
+// executeTask runs a task in its own worktree and records the run on the task board
+func (a *App) executeTask(targetWorkspace *Workspace, taskID int, taskTitle, taskDescription, baseBranch string, options TaskRunOptions) TaskExecutionResult {
+	workspaceName := targetWorkspace.Name
+	branchName := generateBranchName(taskID, taskTitle)
+	worktreePath := filepath.Join(filepath.Dir(targetWorkspace.Path), fmt.Sprintf("task-%d-%s", taskID, workspaceName))
+
+	// Refuse to run tasks whose dependencies are unfinished, unless they can be stacked on
+	dependencyBranches, blockers, err := a.resolveTaskDependencies(workspaceName, taskID, options)
+	if err != nil {
+		return TaskExecutionResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	if len(blockers) > 0 {
+		message := fmt.Sprintf("Task %d is blocked by unfinished dependencies: %s", taskID, formatBlockers(blockers))
+		for _, blocker := range blockers {
+			if isDependencyStackable(blocker) {
+				message += ". Run it with stackOnDependencies to base it on the pushed dependency branches"
+				break
+			}
+		}
+		return TaskExecutionResult{
+			Success:   false,
+			Message:   message,
+			BlockedBy: blockers,
 		}
 	}
 
-	if targetWorkspace == nil {
+	// Register the run before marking the task as running, so it can be cancelled from the moment
+	// it shows as running; both reject tasks that are already running
+	ctx, release, err := a.beginTaskRun(workspaceName, taskID)
+	if err != nil {
 		return TaskExecutionResult{
 			Success: false,
-			Message: fmt.Sprintf("Workspace '%s' not found", workspaceName),
+			Message: fmt.Sprintf("Cannot run task %d: %v", taskID, err),
+		}
+	}
+	if err := a.startTaskRun(workspaceName, taskID, taskTitle, taskDescription, branchName, worktreePath); err != nil {
+		release()
+		return TaskExecutionResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	runConfig, err := a.claudeRunConfig(workspaceName, taskID)
+	if err != nil {
+		release()
+		result := TaskExecutionResult{
+			Success: false,
+			Message: err.Error(),
+		}
+		a.finishTaskRun(workspaceName, taskID, result)
+		return result
+	}
+	runConfig = claude.DefaultTaskRunConfig().Merge(runConfig)
+
+	// Don't start Claude for a run that was cancelled while it was being set up
+	if ctx.Err() != nil {
+		release()
+		result := TaskExecutionResult{
+			Success:   false,
+			Cancelled: true,
+			Message:   fmt.Sprintf("Task %d was cancelled before it started", taskID),
 		}
+		a.finishTaskRun(workspaceName, taskID, result)
+		return result
+	}
+
+	result := a.executeTaskInWorktree(ctx, targetWorkspace, taskID, taskTitle, taskDescription, baseBranch, branchName, worktreePath, dependencyBranches, runConfig)
+	release()
+	result.RunConfig = &runConfig
+
+	return a.completeTaskRun(targetWorkspace, taskID, baseBranch, result)
+}
+
+// completeTaskRun records the outcome of a run and opens a pull request for the branch it pushed
+// if the workspace asks for it. Branches pushed despite failed verification (onFailure "mark")
+// get one too, so reviewers see the failure listed in its body.
+func (a *App) completeTaskRun(targetWorkspace *Workspace, taskID int, baseBranch string, result TaskExecutionResult) TaskExecutionResult {
+	a.finishTaskRun(targetWorkspace.Name, taskID, result)
+
+	if result.Pushed {
+		a.autoOpenPullRequest(targetWorkspace, taskID, baseBranch, &result)
 	}
+	return result
+}
+
+// prepareTaskWorktree creates the task worktree from the base branch and stacks it on the given
+// dependency branches. Setup is serialized because concurrent fetches and worktree commands on
+// the same repository contend for Git's lock files.
+func (a *App) prepareTaskWorktree(targetWorkspace *Workspace, baseBranch, branchName, worktreePath string, dependencyBranches []string) TaskExecutionResult {
+	a.worktreeMu.Lock()
+	defer a.worktreeMu.Unlock()
 
 	// Open the main Git repository
 	repo, err := git.PlainOpen(targetWorkspace.Path)
@@ -1315,12 +1668,14 @@ func (a *App) RunTask(workspaceName string, taskID int, taskTitle, taskDescripti
 		}
 	}
 
-	// Step 2: Generate branch name and worktree path
-	branchName := generateBranchName(taskID, taskTitle)
-	worktreePath := filepath.Join(filepath.Dir(targetWorkspace.Path), fmt.Sprintf("task-%d-%s", taskID, workspaceName))
-
-	// Clean up any existing worktree directory (in case of previous failure)
+	// Step 2: Clean up any existing worktree directory (in case of previous failure)
 	if _, err := os.Stat(worktreePath); err == nil {
+		// Try to remove using git worktree first
+		cleanupCmd := exec.Command("git", "worktree", "remove", "--force", worktreePath)
+		cleanupCmd.Dir = targetWorkspace.Path
+		cleanupCmd.Run() // Ignore errors
+
+		// Ensure directory is gone
 		os.RemoveAll(worktreePath)
 	}
 
@@ -1356,20 +1711,143 @@ func (a *App) RunTask(workspaceName string, taskID int, taskTitle, taskDescripti
 		return result
 	}
 
-	// Step 5: Initialize Claude client with the worktree path
+	// Step 5: Stack the task on the pushed branches of unfinished dependencies
+	if err := a.mergeDependencyBranches(targetWorkspace.Name, worktreePath, dependencyBranches); err != nil {
+		return TaskExecutionResult{
+			Success:      false,
+			Message:      err.Error(),
+			BranchName:   branchName,
+			WorktreePath: worktreePath,
+		}
+	}
+
+	return TaskExecutionResult{
+		Success:      true,
+		Message:      result.Message,
+		BranchName:   branchName,
+		WorktreePath: worktreePath,
+	}
+}
+
+// TaskStreamMessage is emitted to the frontend for every assistant message, tool use and file
+// edit of a running Claude session
+type TaskStreamMessage struct {
+	Workspace string `json:"workspace,omitempty"`
+	TaskID    int    `json:"taskId,omitempty"`
+	claude.StreamEvent
+}
+
+// recordClaudeOutput copies the transcript, summary, usage and tool invocations of a Claude session onto the result
+func (r *TaskExecutionResult) recordClaudeOutput(claudeResult claude.TaskExecutionResult) {
+	r.ClaudeOutput = claudeResult.Transcript
+	r.ClaudeSummary = claudeResult.Summary
+	r.Usage = claudeResult.Usage
+	r.ToolInvocations = claudeResult.ToolInvocations
+}
+
+// taskStreamEventName returns the event on which the Claude progress of a task is emitted
+func taskStreamEventName(workspaceName string, taskID int) string {
+	return fmt.Sprintf("task:%s:%d:stream", workspaceName, taskID)
+}
+
+// sessionStreamEventName returns the event on which the progress of a Claude session that does
+// not belong to a stored task is emitted
+func sessionStreamEventName(sessionID string) string {
+	return fmt.Sprintf("session:%s:stream", sessionID)
+}
+
+// taskStreamHandler forwards the Claude progress of a task to the frontend
+func (a *App) taskStreamHandler(workspaceName string, taskID int) claude.StreamHandler {
+	eventName := taskStreamEventName(workspaceName, taskID)
+	return func(event claude.StreamEvent) {
+		a.emitEvent(eventName, TaskStreamMessage{Workspace: workspaceName, TaskID: taskID, StreamEvent: event})
+	}
+}
+
+// sessionStreamHandler forwards the progress of a Claude session to the frontend
+func (a *App) sessionStreamHandler(sessionID string) claude.StreamHandler {
+	eventName := sessionStreamEventName(sessionID)
+	return func(event claude.StreamEvent) {
+		a.emitEvent(eventName, TaskStreamMessage{StreamEvent: event})
+	}
+}
+
+// taskBrief describes a task for Claude. The title and description are the ones the task was
+// started with; acceptance criteria, files and test plan come from the stored task, if any.
+func (a *App) taskBrief(workspaceName string, taskID int, taskTitle, taskDescription string) claude.TaskBrief {
+	brief := claude.TaskBrief{ID: taskID, Title: taskTitle, Description: taskDescription}
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return brief
+	}
+	if index := board.findTask(taskID); index >= 0 {
+		task := board.Tasks[index]
+		brief.AcceptanceCriteria = task.AcceptanceCriteria
+		brief.Files = task.Files
+		brief.TestPlan = task.TestPlan
+	}
+	return brief
+}
+
+// executeTaskInWorktree creates the task worktree from the base branch and the given dependency
+// branches, runs Claude Code in it and commits and pushes any resulting changes
+func (a *App) executeTaskInWorktree(ctx context.Context, targetWorkspace *Workspace, taskID int, taskTitle, taskDescription, baseBranch, branchName, worktreePath string, dependencyBranches []string, runConfig claude.RunConfig) (result TaskExecutionResult) {
+	result = a.prepareTaskWorktree(targetWorkspace, baseBranch, branchName, worktreePath, dependencyBranches)
+	if !result.Success {
+		return result
+	}
+
+	if ctx.Err() != nil {
+		return TaskExecutionResult{
+			Success:      false,
+			Cancelled:    true,
+			Message:      fmt.Sprintf("Task %d was cancelled before Claude Code started", taskID),
+			BranchName:   branchName,
+			WorktreePath: worktreePath,
+		}
+	}
+
+	// Step 6: Initialize Claude client with the worktree path and stream its progress to the frontend
 	claudeClient := claude.NewClaudeClient(worktreePath)
+	claudeClient.SetStreamHandler(a.taskStreamHandler(targetWorkspace.Name, taskID))
+	claudeClient.SetRunConfig(runConfig)
 
 	// Execute the task using Claude Code in the worktree
-	claudeResult := claudeClient.ExecuteTask(taskID, taskTitle, taskDescription)
+	startedAt := time.Now()
+	claudeResult := claudeClient.ExecuteTaskBrief(ctx, a.taskBrief(targetWorkspace.Name, taskID, taskTitle, taskDescription))
+
+	// Whatever happens next, report what Claude did and keep it in the session transcript
+	defer func() {
+		result.recordClaudeOutput(claudeResult)
+		a.recordSessionTurn(targetWorkspace.Name, taskID, worktreePath, startedAt, claudeResult, result)
+	}()
+
+	// A cancelled run may have stopped halfway through an edit, so nothing of it is committed
+	if ctx.Err() != nil {
+		message := fmt.Sprintf("Task %d was cancelled; uncommitted changes were discarded", taskID)
+		if err := discardWorktreeChanges(worktreePath); err != nil {
+			message = fmt.Sprintf("Task %d was cancelled, but discarding its uncommitted changes failed: %v", taskID, err)
+		}
+		return TaskExecutionResult{
+			Success:      false,
+			Cancelled:    true,
+			Message:      message,
+			BranchName:   branchName,
+			SessionID:    claudeResult.SessionID,
+			WorktreePath: worktreePath,
+		}
+	}
+
 	if !claudeResult.Success {
 		return TaskExecutionResult{
-			Success:    false,
-			Message:    fmt.Sprintf("Claude Code execution failed: %s", claudeResult.Message),
-			BranchName: branchName,
+			Success:      false,
+			Message:      fmt.Sprintf("Claude Code execution failed: %s", claudeResult.Message),
+			BranchName:   branchName,
+			WorktreePath: worktreePath,
 		}
 	}
 
-	// Step 6: Check for any changes in the worktree and commit/push if found
+	// Step 7: Check for any changes in the worktree and commit/push if found
 	hasChanges, changedFiles := a.checkForGitChanges(worktreePath)
 	if hasChanges {
 		// Use detected files if Claude didn't report any, otherwise use Claude's list
@@ -1378,17 +1856,76 @@ func (a *App) RunTask(workspaceName string, taskID int, taskTitle, taskDescripti
 			filesToCommit = changedFiles
 		}
 
-		commitResult := a.commitAndPushFromWorktree(worktreePath, branchName, taskID, taskTitle, taskDescription, filesToCommit)
+		// Step 8: Run the workspace's verification steps before anything is committed
+		verification, verificationConfig, err := a.verifyWorktree(ctx, targetWorkspace.Name, worktreePath)
+		if err != nil {
+			return TaskExecutionResult{
+				Success:      false,
+				Message:      err.Error(),
+				BranchName:   branchName,
+				SessionID:    claudeResult.SessionID,
+				WorktreePath: worktreePath,
+			}
+		}
+		if ctx.Err() != nil {
+			message := fmt.Sprintf("Task %d was cancelled during verification; uncommitted changes were discarded", taskID)
+			if err := discardWorktreeChanges(worktreePath); err != nil {
+				message = fmt.Sprintf("Task %d was cancelled during verification, but discarding its uncommitted changes failed: %v", taskID, err)
+			}
+			return TaskExecutionResult{
+				Success:      false,
+				Cancelled:    true,
+				Message:      message,
+				BranchName:   branchName,
+				SessionID:    claudeResult.SessionID,
+				WorktreePath: worktreePath,
+				Verification: verification,
+			}
+		}
+		verificationFailed := verification != nil && !verification.Passed
+		if verificationFailed && verificationConfig.blocksCommit() {
+			return TaskExecutionResult{
+				Success:      false,
+				Message:      fmt.Sprintf("Task %d was not committed: %s. The changes are left in worktree '%s'", taskID, verification.summary(), worktreePath),
+				BranchName:   branchName,
+				FilesChanged: changedFiles,
+				SessionID:    claudeResult.SessionID,
+				WorktreePath: worktreePath,
+				Verification: verification,
+			}
+		}
+
+		commitResult := a.commitAndPushFromWorktree(targetWorkspace.Name, worktreePath, branchName, taskID, taskTitle, taskDescription, filesToCommit)
 		if !commitResult.Success {
+			commitResult.BranchName = branchName
+			commitResult.SessionID = claudeResult.SessionID
+			commitResult.WorktreePath = worktreePath
+			commitResult.Verification = verification
 			return commitResult
 		}
 
+		if verificationFailed {
+			return TaskExecutionResult{
+				Success:      false,
+				Message:      fmt.Sprintf("Task %d was committed and pushed to branch '%s', but %s", taskID, branchName, verification.summary()),
+				BranchName:   branchName,
+				FilesChanged: changedFiles,
+				SessionID:    claudeResult.SessionID,
+				WorktreePath: worktreePath,
+				Verification: verification,
+				Pushed:       true,
+			}
+		}
+
 		return TaskExecutionResult{
 			Success:      true,
 			Message:      fmt.Sprintf("Successfully executed task %d, committed %d files, and pushed to branch '%s' (based on '%s')", taskID, len(changedFiles), branchName, baseBranch),
 			BranchName:   branchName,
 			FilesChanged: changedFiles,
-			ClaudeOutput: claudeResult.Message,
+			SessionID:    claudeResult.SessionID,
+			WorktreePath: worktreePath,
+			Verification: verification,
+			Pushed:       true,
 		}
 	}
 
@@ -1398,7 +1935,8 @@ func (a *App) RunTask(workspaceName string, taskID int, taskTitle, taskDescripti
 		Message:      fmt.Sprintf("Successfully executed task %d but no file changes were detected in worktree at '%s' on branch '%s' (based on '%s')", taskID, worktreePath, branchName, baseBranch),
 		BranchName:   branchName,
 		FilesChanged: []string{},
-		ClaudeOutput: claudeResult.Message,
+		SessionID:    claudeResult.SessionID,
+		WorktreePath: worktreePath,
 	}
 }
 
@@ -1557,7 +2095,7 @@ func (a *App) checkForGitChanges(worktreePath string) (bool, []string) {
 }
 
 // commitAndPushFromWorktree commits and pushes changes from a git worktree
-func (a *App) commitAndPushFromWorktree(worktreePath, branchName string, taskID int, taskTitle, taskDescription string, filesChanged []string) TaskExecutionResult {
+func (a *App) commitAndPushFromWorktree(workspaceName, worktreePath, branchName string, taskID int, taskTitle, taskDescription string, filesChanged []string) TaskExecutionResult {
 	// Add all changed files
 	if len(filesChanged) > 0 {
 		// Try to add specific files that were reported as changed
@@ -1598,18 +2136,16 @@ func (a *App) commitAndPushFromWorktree(worktreePath, branchName string, taskID
 		}
 	}
 
-	// Create commit with detailed message
-	commitMsg := fmt.Sprintf("feat: %s\n\nTask #%d: %s\n\n%s", taskTitle, taskID, taskTitle, taskDescription)
-	cmd := exec.Command("git", "commit", "-m", commitMsg)
-	cmd.Dir = worktreePath
-
-	// Set author for the commit
-	cmd.Env = append(os.Environ(),
-		"GIT_AUTHOR_NAME=Claude Code",
-		"GIT_AUTHOR_EMAIL=claude@anthropic.com",
-		"GIT_COMMITTER_NAME=Claude Code",
-		"GIT_COMMITTER_EMAIL=claude@anthropic.com",
-	)
+	// Create commit with detailed message, attributed as the workspace configures
+	setup, err := a.commitSetup(workspaceName, worktreePath)
+	if err != nil {
+		return TaskExecutionResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	commitMsg := setup.message(fmt.Sprintf("feat: %s\n\nTask #%d: %s\n\n%s", taskTitle, taskID, taskTitle, taskDescription))
+	cmd := setup.command(worktreePath, "commit", "-m", commitMsg)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -1728,6 +2264,14 @@ func (a *App) CleanupTaskWorktree(workspaceName string, taskID int) TaskExecutio
 	pruneCmd.Dir = targetWorkspace.Path
 	pruneCmd.Run() // Ignore errors
 
+	// Forget the worktree on the task board
+	a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		if index := board.findTask(taskID); index >= 0 {
+			board.Tasks[index].WorktreePath = ""
+		}
+		return nil
+	})
+
 	return TaskExecutionResult{
 		Success: true,
 		Message: fmt.Sprintf("Successfully cleaned up worktree for task %d", taskID),
@@ -1789,13 +2333,40 @@ func (a *App) DeleteTask(workspaceName string, taskID int) DeleteTaskResult {
 		fmt.Printf("Warning: Failed to cleanup worktree for task %d: %s\n", taskID, cleanupResult.Message)
 	}
 
+	// Remove the task from the stored board along with any dependencies on it
+	_, err := a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		if index := board.findTask(taskID); index >= 0 {
+			board.Tasks = append(board.Tasks[:index], board.Tasks[index+1:]...)
+		}
+		for i := range board.Tasks {
+			dependencies := []int{}
+			for _, depID := range board.Tasks[i].Dependencies {
+				if depID != taskID {
+					dependencies = append(dependencies, depID)
+				}
+			}
+			board.Tasks[i].Dependencies = dependencies
+		}
+		return nil
+	})
+	if err != nil {
+		return DeleteTaskResult{
+			Success: false,
+			Message: fmt.Sprintf("Cleaned up task %d but failed to remove it from the task board: %v", taskID, err),
+		}
+	}
+
+	if err := a.sessions.DeleteTask(workspaceName, taskID); err != nil {
+		fmt.Printf("Warning: Failed to delete session transcripts of task %d: %v\n", taskID, err)
+	}
+
 	return DeleteTaskResult{
 		Success: true,
 		Message: fmt.Sprintf("Successfully deleted task %d and cleaned up any associated worktree", taskID),
 	}
 }
 
-// StartTaskConversation starts a new Claude session for a task (simplified - no conversation storage)
+// StartTaskConversation starts a new Claude session for a task that can be continued with ContinueClaudeSession
 func (a *App) StartTaskConversation(workspaceName string, taskID int, taskTitle, taskDescription, baseBranch string) TaskExecutionResult {
 	// Validate input parameters
 	if strings.TrimSpace(workspaceName) == "" {
@@ -1826,102 +2397,22 @@ func (a *App) StartTaskConversation(workspaceName string, taskID int, taskTitle,
 		}
 	}
 
-	// Get workspaces to find the target workspace
-	workspacesResult := a.GetWorkspaces()
-	if !workspacesResult.Success {
-		return TaskExecutionResult{
-			Success: false,
-			Message: workspacesResult.Message,
-		}
-	}
-
 	// Find the specified workspace
-	var targetWorkspace *Workspace
-	for i := range workspacesResult.Workspaces {
-		if workspacesResult.Workspaces[i].Name == workspaceName {
-			targetWorkspace = &workspacesResult.Workspaces[i]
-			break
-		}
-	}
-
-	if targetWorkspace == nil {
-		return TaskExecutionResult{
-			Success: false,
-			Message: fmt.Sprintf("Workspace '%s' not found", workspaceName),
-		}
-	}
-
-	// Setup Git worktree
-	repo, err := git.PlainOpen(targetWorkspace.Path)
+	targetWorkspace, err := a.findWorkspace(workspaceName)
 	if err != nil {
 		return TaskExecutionResult{
 			Success: false,
-			Message: fmt.Sprintf("Failed to open Git repository: %v", err),
+			Message: err.Error(),
 		}
 	}
 
-	// Fetch from origin
-	err = repo.Fetch(&git.FetchOptions{
-		RemoteName: "origin",
-	})
-	if err != nil && err != git.NoErrAlreadyUpToDate {
-		return TaskExecutionResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to fetch from origin: %v", err),
-		}
-	}
-
-	// Generate branch name and worktree path
-	branchName := generateBranchName(taskID, taskTitle)
-	worktreePath := filepath.Join(filepath.Dir(targetWorkspace.Path), fmt.Sprintf("task-%d-%s", taskID, workspaceName))
-
-	// Clean up any existing worktree directory with improved error handling
-	if _, err := os.Stat(worktreePath); err == nil {
-		// Try to remove using git worktree first
-		cleanupCmd := exec.Command("git", "worktree", "remove", "--force", worktreePath)
-		cleanupCmd.Dir = targetWorkspace.Path
-		cleanupCmd.Run() // Ignore errors
-
-		// Ensure directory is gone
-		os.RemoveAll(worktreePath)
-	}
-
-	// Create worktree
-	result := a.executeGitWorktreeCommands(targetWorkspace.Path, worktreePath, baseBranch, branchName)
+	result := a.executeTask(targetWorkspace, taskID, taskTitle, taskDescription, baseBranch, TaskRunOptions{})
 	if !result.Success {
 		return result
 	}
 
-	// Initialize Claude client with the worktree path
-	claudeClient := claude.NewClaudeClient(worktreePath)
-
-	// Start the Claude session
-	claudeResult := claudeClient.ExecuteTask(taskID, taskTitle, taskDescription)
-	if !claudeResult.Success {
-		return TaskExecutionResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to start Claude session: %s", claudeResult.Message),
-		}
-	}
-
-	// Check for changes and commit if found
-	hasChanges, changedFiles := a.checkForGitChanges(worktreePath)
-	if hasChanges {
-		commitResult := a.commitAndPushFromWorktree(worktreePath, branchName, taskID, taskTitle, taskDescription, changedFiles)
-		if !commitResult.Success {
-			return commitResult
-		}
-	}
-
-	return TaskExecutionResult{
-		Success:      true,
-		Message:      fmt.Sprintf("Started Claude session for task %d on branch '%s'", taskID, branchName),
-		BranchName:   branchName,
-		ClaudeOutput: claudeResult.Message,
-		FilesChanged: changedFiles,
-		SessionID:    claudeResult.SessionID,
-		WorktreePath: worktreePath,
-	}
+	result.Message = fmt.Sprintf("Started Claude session for task %d on branch '%s'", taskID, result.BranchName)
+	return result
 }
 
 // ContinueClaudeSession continues a Claude session using sessionId and worktree path
@@ -1959,11 +2450,122 @@ func (a *App) ContinueClaudeSession(sessionID, userMessage, worktreePath string)
 		}
 	}
 
-	// Initialize Claude client with the specific worktree path
-	claudeClient := claude.NewClaudeClient(worktreePath)
+	return a.continueSession(sessionID, userMessage, userMessage, worktreePath)
+}
+
+// continueSession continues a Claude session in a worktree and tracks the run on the task board
+// if the worktree belongs to a stored task. request describes the turn in the commit message.
+func (a *App) continueSession(sessionID, userMessage, request, worktreePath string) ClaudeSessionResult {
+	// Track the run on the task board if the worktree belongs to a stored task, which also
+	// makes it cancellable with CancelTask
+	ctx, release := context.Background(), func() {}
+	workspaceName, taskID, tracked := a.findTaskForWorktree(worktreePath)
+	if tracked {
+		// Register the run first, so it can be cancelled from the moment the task shows as running
+		var err error
+		ctx, release, err = a.beginTaskRun(workspaceName, taskID)
+		if err != nil {
+			return ClaudeSessionResult{
+				Success: false,
+				Message: fmt.Sprintf("Cannot continue session: %v", err),
+			}
+		}
+		_, err = a.transitionTask(workspaceName, taskID, TaskStatusRunning, func(task *Task) {
+			now := time.Now()
+			task.LastRunAt = &now
+			task.LastError = ""
+			task.SessionID = sessionID
+		})
+		if err != nil {
+			release()
+			return ClaudeSessionResult{
+				Success: false,
+				Message: fmt.Sprintf("Cannot continue session: %v", err),
+			}
+		}
+	}
+
+	// Stream progress on the task's channel, or on the session's channel for worktrees outside the task board
+	streamHandler := a.sessionStreamHandler(sessionID)
+	if tracked {
+		streamHandler = a.taskStreamHandler(workspaceName, taskID)
+	}
+
+	// Sessions outside the task board only use the app-wide run config
+	var result ClaudeSessionResult
+	runConfig, err := a.claudeRunConfig(workspaceName, taskID)
+	if err != nil {
+		result = ClaudeSessionResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	} else {
+		runConfig = claude.DefaultConversationRunConfig().Merge(runConfig)
+
+		claudeClient := claude.NewClaudeClient(worktreePath)
+		claudeClient.SetStreamHandler(streamHandler)
+		claudeClient.SetRunConfig(runConfig)
+
+		result = a.continueClaudeSession(ctx, claudeClient, sessionID, userMessage, request, worktreePath)
+		result.RunConfig = &runConfig
+	}
+	release()
+
+	if tracked {
+		a.finishTaskRun(workspaceName, taskID, TaskExecutionResult{
+			Success:       result.Success,
+			Message:       result.Message,
+			FilesChanged:  result.FilesChanged,
+			SessionID:     sessionID,
+			Cancelled:     result.Cancelled,
+			RunConfig:     result.RunConfig,
+			ClaudeSummary: result.Summary,
+			Usage:         result.Usage,
+			Verification:  result.Verification,
+			Pushed:        result.Pushed,
+		})
+	}
+	return result
+}
+
+// continueClaudeSession resumes the Claude session in the worktree and commits and pushes any resulting changes
+func (a *App) continueClaudeSession(ctx context.Context, claudeClient *claude.ClaudeClient, sessionID, userMessage, request, worktreePath string) (result ClaudeSessionResult) {
+	// Worktrees outside the known workspaces get no transcript, verification or commit identity
+	taskID, workspaceName, inWorkspace := a.parseKnownWorktreeDirectory(worktreePath)
 
 	// Continue the Claude session
-	claudeResult := claudeClient.ContinueConversation(sessionID, userMessage)
+	startedAt := time.Now()
+	claudeResult := claudeClient.ContinueConversationContext(ctx, sessionID, userMessage)
+
+	// Whatever happens next, report what Claude did and keep it in the session transcript of its task
+	defer func() {
+		result.Summary = claudeResult.Summary
+		result.Usage = claudeResult.Usage
+		result.ToolInvocations = claudeResult.ToolInvocations
+
+		if inWorkspace {
+			a.recordSessionTurn(workspaceName, taskID, worktreePath, startedAt, claudeResult, TaskExecutionResult{
+				Success:      result.Success,
+				Message:      result.Message,
+				FilesChanged: result.FilesChanged,
+				Cancelled:    result.Cancelled,
+				Pushed:       result.Pushed,
+			})
+		}
+	}()
+
+	// Discard the partial work of a cancelled session instead of committing it
+	if ctx.Err() != nil {
+		message := "Claude session was cancelled; uncommitted changes were discarded"
+		if err := discardWorktreeChanges(worktreePath); err != nil {
+			message = fmt.Sprintf("Claude session was cancelled, but discarding its uncommitted changes failed: %v", err)
+		}
+		return ClaudeSessionResult{
+			Success:   false,
+			Cancelled: true,
+			Message:   message,
+		}
+	}
 	if !claudeResult.Success {
 		return ClaudeSessionResult{
 			Success: false,
@@ -1980,6 +2582,41 @@ func (a *App) ContinueClaudeSession(sessionID, userMessage, worktreePath string)
 			filesToCommit = changedFiles
 		}
 
+		// Run the verification steps of the task's workspace before anything is committed
+		var verification *VerificationResult
+		if inWorkspace {
+			var verificationConfig VerificationConfig
+			var err error
+			verification, verificationConfig, err = a.verifyWorktree(ctx, workspaceName, worktreePath)
+			if err != nil {
+				return ClaudeSessionResult{
+					Success: false,
+					Message: err.Error(),
+				}
+			}
+			if ctx.Err() != nil {
+				message := "Claude session was cancelled during verification; uncommitted changes were discarded"
+				if err := discardWorktreeChanges(worktreePath); err != nil {
+					message = fmt.Sprintf("Claude session was cancelled during verification, but discarding its uncommitted changes failed: %v", err)
+				}
+				return ClaudeSessionResult{
+					Success:      false,
+					Cancelled:    true,
+					Message:      message,
+					Verification: verification,
+				}
+			}
+			if verification != nil && !verification.Passed && verificationConfig.blocksCommit() {
+				return ClaudeSessionResult{
+					Success:      false,
+					Message:      fmt.Sprintf("Changes were not committed: %s. They are left in the worktree", verification.summary()),
+					Response:     claudeResult.Message,
+					FilesChanged: filesToCommit,
+					Verification: verification,
+				}
+			}
+		}
+
 		// Extract branch information for commit and push
 
 		// Get the branch name from git
@@ -1991,11 +2628,20 @@ func (a *App) ContinueClaudeSession(sessionID, userMessage, worktreePath string)
 			branchName = strings.TrimSpace(string(branchOutput))
 		}
 
-		// Create a simple commit message for continued session
-		commitMsg := fmt.Sprintf("Update from continued Claude session\n\nUser request: %s\n\nFiles modified:\n", userMessage)
+		// Create a simple commit message for continued session. Worktrees outside the known
+		// workspaces are committed with the default identity.
+		setup, err := a.commitSetup(workspaceName, worktreePath)
+		if err != nil {
+			return ClaudeSessionResult{
+				Success: false,
+				Message: err.Error(),
+			}
+		}
+		commitMsg := fmt.Sprintf("Update from continued Claude session\n\nUser request: %s\n\nFiles modified:\n", request)
 		for _, file := range filesToCommit {
 			commitMsg += fmt.Sprintf("- %s\n", file)
 		}
+		commitMsg = setup.message(commitMsg)
 
 		// Commit changes
 		cmd = exec.Command("git", "add", ".")
@@ -2007,15 +2653,7 @@ func (a *App) ContinueClaudeSession(sessionID, userMessage, worktreePath string)
 			}
 		}
 
-		cmd = exec.Command("git", "commit", "-m", commitMsg)
-		cmd.Dir = worktreePath
-		cmd.Env = append(os.Environ(),
-			"GIT_AUTHOR_NAME=Claude Code",
-			"GIT_AUTHOR_EMAIL=claude@anthropic.com",
-			"GIT_COMMITTER_NAME=Claude Code",
-			"GIT_COMMITTER_EMAIL=claude@anthropic.com",
-		)
-
+		cmd = setup.command(worktreePath, "commit", "-m", commitMsg)
 		if err := cmd.Run(); err != nil {
 			return ClaudeSessionResult{
 				Success: false,
@@ -2033,11 +2671,24 @@ func (a *App) ContinueClaudeSession(sessionID, userMessage, worktreePath string)
 			}
 		}
 
+		if verification != nil && !verification.Passed {
+			return ClaudeSessionResult{
+				Success:      false,
+				Message:      fmt.Sprintf("Committed and pushed %d files to branch '%s', but %s", len(filesToCommit), branchName, verification.summary()),
+				Response:     claudeResult.Message,
+				FilesChanged: filesToCommit,
+				Verification: verification,
+				Pushed:       true,
+			}
+		}
+
 		return ClaudeSessionResult{
 			Success:      true,
 			Message:      fmt.Sprintf("Claude session continued successfully. Committed and pushed %d files to branch '%s'", len(filesToCommit), branchName),
 			Response:     claudeResult.Message,
 			FilesChanged: filesToCommit,
+			Verification: verification,
+			Pushed:       true,
 		}
 	}
 
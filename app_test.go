@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
+
+	"specprint/pkg/llm"
 )
 
 func TestGenerateTasks(t *testing.T) {
@@ -168,3 +171,64 @@ func TestGenerateTasksFromWorkspacePRDEmptyName(t *testing.T) {
 		t.Errorf("Expected error message about empty workspace name, got: %s", result.Message)
 	}
 }
+
+// scriptedGenerator replies to each request with the next of its canned responses
+type scriptedGenerator struct {
+	responses []string
+	requests  []llm.Request
+}
+
+func (g *scriptedGenerator) Name() string {
+	return "Scripted"
+}
+
+func (g *scriptedGenerator) Complete(ctx context.Context, req llm.Request) (string, error) {
+	g.requests = append(g.requests, req)
+	if len(g.requests) > len(g.responses) {
+		return "", fmt.Errorf("no more responses")
+	}
+	return g.responses[len(g.requests)-1], nil
+}
+
+func TestGenerateTasksRepairsGraphWithProvider(t *testing.T) {
+	generator := &scriptedGenerator{responses: []string{
+		`[{"id":1,"title":"Set up","description":"Init repo","dependencies":[2],"priority":"high","estimate":"1h"},
+		  {"id":2,"title":"Build","description":"Build app","dependencies":[1],"priority":"high","estimate":"1d"}]`,
+		`[{"id":1,"title":"Set up","description":"Init repo","dependencies":[],"priority":"high","estimate":"1h"},
+		  {"id":2,"title":"Build","description":"Build app","dependencies":[1],"priority":"high","estimate":"1d"}]`,
+	}}
+
+	result := generateTasks(generator, "Build an app", TaskGenerationOptions{RepairAttempts: 1})
+	if !result.Success || len(result.Tasks) != 2 || result.RepairAttempts != 1 {
+		t.Fatalf("Expected repaired tasks, got %+v", result)
+	}
+
+	repair := generator.requests[1].Messages
+	if len(repair) != 4 || repair[2].Role != llm.RoleAssistant || repair[3].Role != llm.RoleUser {
+		t.Errorf("Expected the repair request to continue the conversation, got %+v", repair)
+	}
+}
+
+func TestGenerateTasksRepairsUnparseableResponse(t *testing.T) {
+	generator := &scriptedGenerator{responses: []string{
+		`Sure! [{"id":1,"title":"Set up",`,
+		"```json\n" + `{"tasks":[{"id":1,"title":"Set up","description":"Init repo","dependencies":[],"priority":"high","estimate":"1h"}]}` + "\n```",
+	}}
+
+	result := generateTasks(generator, "Build an app", TaskGenerationOptions{RepairAttempts: 1})
+	if !result.Success || len(result.Tasks) != 1 || result.RepairAttempts != 1 {
+		t.Fatalf("Expected repaired tasks, got %+v", result)
+	}
+	if generator.requests[0].Schema == nil {
+		t.Error("Expected the request to carry the task list schema")
+	}
+	if feedback := generator.requests[1].Messages[3].Content; !strings.Contains(feedback, "could not be parsed") {
+		t.Errorf("Expected the parse error to be fed back, got: %s", feedback)
+	}
+
+	// Without repair attempts the parse error fails generation
+	generator = &scriptedGenerator{responses: []string{"No tasks"}}
+	if result := generateTasks(generator, "Build an app", TaskGenerationOptions{}); result.Success || !strings.Contains(result.Message, "Failed to parse") {
+		t.Errorf("Expected parse failure, got %+v", result)
+	}
+}
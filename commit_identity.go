@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultCommitAuthor is who task commits are attributed to unless a workspace configures it
+var defaultCommitAuthor = GitIdentity{Name: "Claude Code", Email: "claude@anthropic.com"}
+
+// GitIdentity is a name and email recorded in commits
+type GitIdentity struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// isEmpty reports whether neither name nor email is set
+func (g GitIdentity) isEmpty() bool {
+	return g.Name == "" && g.Email == ""
+}
+
+// String formats the identity as git does, e.g. "Jane Doe <jane@example.com>"
+func (g GitIdentity) String() string {
+	return fmt.Sprintf("%s <%s>", g.Name, g.Email)
+}
+
+// validate checks that a set identity has both a name and an email that git accepts
+func (g GitIdentity) validate() error {
+	if strings.TrimSpace(g.Name) == "" || strings.TrimSpace(g.Email) == "" {
+		return fmt.Errorf("'%s' needs both a name and an email", g)
+	}
+	if strings.ContainsAny(g.Name+g.Email, "<>\n") {
+		return fmt.Errorf("'%s' must not contain '<', '>' or line breaks", g)
+	}
+	return nil
+}
+
+// CommitIdentity controls who the commits of a workspace's tasks are attributed to and whether
+// they are signed
+type CommitIdentity struct {
+	// Author of task commits. Empty uses "Claude Code <claude@anthropic.com>", or the user's
+	// git config with UseGitConfig.
+	Author    GitIdentity `json:"author"`
+	Committer GitIdentity `json:"committer"` // Defaults to the author
+	// UseGitConfig attributes commits to the user.name and user.email of the user's git config
+	UseGitConfig bool `json:"useGitConfig,omitempty"`
+	// CoAuthors are credited with Co-authored-by trailers
+	CoAuthors []GitIdentity `json:"coAuthors,omitempty"`
+	// CoAuthorGitUser credits the user who triggered the run, as named by their git config,
+	// with a Co-authored-by trailer
+	CoAuthorGitUser bool `json:"coAuthorGitUser,omitempty"`
+	// Sign signs commits with the key and format (GPG or SSH) of the user's git config
+	Sign bool `json:"sign,omitempty"`
+}
+
+// Validate checks that every configured identity is complete
+func (c CommitIdentity) Validate() error {
+	for _, identity := range []GitIdentity{c.Author, c.Committer} {
+		if !identity.isEmpty() {
+			if err := identity.validate(); err != nil {
+				return err
+			}
+		}
+	}
+	for _, coAuthor := range c.CoAuthors {
+		if err := coAuthor.validate(); err != nil {
+			return fmt.Errorf("co-author %v", err)
+		}
+	}
+	return nil
+}
+
+// gitConfigIdentity reads user.name and user.email from the git config that applies in dir
+func gitConfigIdentity(dir string) (GitIdentity, error) {
+	var identity GitIdentity
+	for key, value := range map[string]*string{"user.name": &identity.Name, "user.email": &identity.Email} {
+		cmd := exec.Command("git", "config", "--get", key)
+		cmd.Dir = dir
+		output, err := cmd.Output()
+		if err != nil || strings.TrimSpace(string(output)) == "" {
+			return GitIdentity{}, fmt.Errorf("%s is not set in your git config", key)
+		}
+		*value = strings.TrimSpace(string(output))
+	}
+	return identity, nil
+}
+
+// commitSetup is a CommitIdentity resolved for the git commands of one worktree
+type commitSetup struct {
+	env      []string // Identity variables added to the environment of git
+	signArgs []string // Arguments that sign the commit, if any
+	trailers []string // Co-authored-by trailers
+}
+
+// setup resolves the identity for the git commands run in dir, reading the user's git config
+// where it is needed
+func (c CommitIdentity) setup(dir string) (commitSetup, error) {
+	var setup commitSetup
+
+	var gitUser GitIdentity
+	if c.UseGitConfig || c.CoAuthorGitUser {
+		var err error
+		if gitUser, err = gitConfigIdentity(dir); err != nil {
+			return commitSetup{}, err
+		}
+	}
+
+	author := c.Author
+	if author.isEmpty() {
+		author = defaultCommitAuthor
+		if c.UseGitConfig {
+			author = gitUser
+		}
+	}
+	committer := c.Committer
+	if committer.isEmpty() {
+		committer = author
+	}
+	setup.env = []string{
+		"GIT_AUTHOR_NAME=" + author.Name,
+		"GIT_AUTHOR_EMAIL=" + author.Email,
+		"GIT_COMMITTER_NAME=" + committer.Name,
+		"GIT_COMMITTER_EMAIL=" + committer.Email,
+	}
+
+	coAuthors := c.CoAuthors
+	if c.CoAuthorGitUser {
+		coAuthors = append([]GitIdentity{gitUser}, coAuthors...)
+	}
+	seen := map[string]bool{strings.ToLower(author.Email): true}
+	for _, coAuthor := range coAuthors {
+		if !seen[strings.ToLower(coAuthor.Email)] {
+			seen[strings.ToLower(coAuthor.Email)] = true
+			setup.trailers = append(setup.trailers, "Co-authored-by: "+coAuthor.String())
+		}
+	}
+
+	if c.Sign {
+		setup.signArgs = []string{"-S"}
+	}
+	return setup, nil
+}
+
+// message appends the Co-authored-by trailers to a commit message
+func (s commitSetup) message(message string) string {
+	if len(s.trailers) == 0 {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + strings.Join(s.trailers, "\n") + "\n"
+}
+
+// command creates a git command that creates commits, e.g. git commit or git merge, with the
+// identity in its environment and the signing arguments after the subcommand
+func (s commitSetup) command(dir, subcommand string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", append(append([]string{subcommand}, s.signArgs...), args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), s.env...)
+	return cmd
+}
+
+// commitSetup resolves the commit identity of a workspace for a worktree. An empty workspace
+// name uses the default identity.
+func (a *App) commitSetup(workspaceName, worktreePath string) (commitSetup, error) {
+	var identity CommitIdentity
+	if workspaceName != "" {
+		settings, err := a.settings.LoadWorkspace(workspaceName)
+		if err != nil {
+			return commitSetup{}, fmt.Errorf("failed to load workspace settings: %v", err)
+		}
+		identity = settings.CommitIdentity
+	}
+
+	setup, err := identity.setup(worktreePath)
+	if err != nil {
+		return commitSetup{}, fmt.Errorf("cannot resolve the commit identity: %v", err)
+	}
+	return setup, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initCommitRepo creates a repository with a staged file whose git config names Jane Doe
+func initCommitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{{"init", "-q"}, {"config", "user.name", "Jane Doe"}, {"config", "user.email", "jane@example.com"}, {"config", "commit.gpgsign", "false"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
+	cmd := exec.Command("git", "add", ".")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+	return dir
+}
+
+func TestCommitIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity CommitIdentity
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: "Claude Code <claude@anthropic.com>|Claude Code <claude@anthropic.com>|Add main\n",
+		},
+		{
+			name: "configured",
+			identity: CommitIdentity{
+				Author:          GitIdentity{Name: "Spec Bot", Email: "bot@example.com"},
+				Committer:       GitIdentity{Name: "CI", Email: "ci@example.com"},
+				CoAuthors:       []GitIdentity{{Name: "Sam Roe", Email: "sam@example.com"}},
+				CoAuthorGitUser: true,
+			},
+			expected: "Spec Bot <bot@example.com>|CI <ci@example.com>|Add main\n\nCo-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: Sam Roe <sam@example.com>\n",
+		},
+		{
+			name:     "git config",
+			identity: CommitIdentity{UseGitConfig: true, CoAuthorGitUser: true},
+			expected: "Jane Doe <jane@example.com>|Jane Doe <jane@example.com>|Add main\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := initCommitRepo(t)
+			setup, err := test.identity.setup(dir)
+			if err != nil {
+				t.Fatalf("Expected the identity to resolve, got: %v", err)
+			}
+			if output, err := setup.command(dir, "commit", "-q", "-m", setup.message("Add main")).CombinedOutput(); err != nil {
+				t.Fatalf("Commit failed: %v\n%s", err, output)
+			}
+
+			cmd := exec.Command("git", "log", "-1", "--format=%an <%ae>|%cn <%ce>|%B")
+			cmd.Dir = dir
+			output, _ := cmd.Output()
+			if got := strings.TrimRight(string(output), "\n") + "\n"; got != test.expected {
+				t.Errorf("Expected commit %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCommitIdentityValidation(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	invalid := []CommitIdentity{
+		{Author: GitIdentity{Name: "Spec Bot"}},
+		{Committer: GitIdentity{Name: "CI <ci>", Email: "ci@example.com"}},
+		{CoAuthors: []GitIdentity{{Email: "sam@example.com"}}},
+	}
+	for _, identity := range invalid {
+		if result := app.SaveWorkspaceSettings("demo", WorkspaceSettings{CommitIdentity: identity}); result.Success {
+			t.Errorf("Expected %+v to be rejected", identity)
+		}
+	}
+
+	setup, err := CommitIdentity{Sign: true}.setup(t.TempDir())
+	if err != nil || len(setup.signArgs) != 1 || setup.signArgs[0] != "-S" {
+		t.Errorf("Expected signed commits to pass -S, got %+v (%v)", setup, err)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// TaskBlocker describes a dependency that keeps a task from running
+type TaskBlocker struct {
+	ID         int        `json:"id"`
+	Title      string     `json:"title,omitempty"`
+	Status     TaskStatus `json:"status,omitempty"`
+	BranchName string     `json:"branchName,omitempty"`
+	Missing    bool       `json:"missing,omitempty"` // The dependency is not on the task board
+}
+
+// DependencyCheckResult represents the result of checking whether a task's dependencies are finished
+type DependencyCheckResult struct {
+	Success   bool          `json:"success"`
+	Message   string        `json:"message"`
+	Ready     bool          `json:"ready"`
+	BlockedBy []TaskBlocker `json:"blockedBy,omitempty"`
+	// StackableBranches are pushed dependency branches the task could be based on instead of waiting
+	StackableBranches []string `json:"stackableBranches,omitempty"`
+}
+
+// TaskRunOptions controls how a task run treats unfinished dependencies
+type TaskRunOptions struct {
+	// StackOnDependencies merges the pushed branches of unfinished dependencies into the
+	// task branch, so the task can build on work that is still awaiting review
+	StackOnDependencies bool `json:"stackOnDependencies"`
+	// IgnoreDependencies runs the task even if its dependencies are unfinished
+	IgnoreDependencies bool `json:"ignoreDependencies"`
+	// SatisfiedDependencies no longer block the task although they are unfinished, e.g. because
+	// they succeeded earlier in the same RunWorkspaceTasks run without changing anything
+	SatisfiedDependencies []int `json:"-"`
+}
+
+// isDependencyFinished checks if a dependency in the given status no longer blocks its dependents
+func isDependencyFinished(status TaskStatus) bool {
+	return status == TaskStatusDone || status == TaskStatusMerged
+}
+
+// isDependencyStackable checks if an unfinished dependency has a pushed branch that dependents can build on
+func isDependencyStackable(blocker TaskBlocker) bool {
+	return !blocker.Missing && blocker.Status == TaskStatusReview && blocker.BranchName != ""
+}
+
+// findDependencyBlockers returns the unfinished dependencies of a task on the board
+func findDependencyBlockers(board TaskBoard, task Task) []TaskBlocker {
+	var blockers []TaskBlocker
+	for _, depID := range task.Dependencies {
+		index := board.findTask(depID)
+		if index < 0 {
+			blockers = append(blockers, TaskBlocker{ID: depID, Missing: true})
+			continue
+		}
+
+		dependency := board.Tasks[index]
+		if isDependencyFinished(dependency.Status) {
+			continue
+		}
+		blockers = append(blockers, TaskBlocker{
+			ID:         dependency.ID,
+			Title:      dependency.Title,
+			Status:     dependency.Status,
+			BranchName: dependency.BranchName,
+		})
+	}
+	return blockers
+}
+
+// formatBlockers renders blockers as a human readable list, e.g. "#1 (running), #4 (missing)"
+func formatBlockers(blockers []TaskBlocker) string {
+	parts := make([]string, 0, len(blockers))
+	for _, blocker := range blockers {
+		state := string(blocker.Status)
+		if blocker.Missing {
+			state = "missing"
+		} else if state == "" {
+			state = string(TaskStatusTodo)
+		}
+		parts = append(parts, fmt.Sprintf("#%d (%s)", blocker.ID, state))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// resolveTaskDependencies checks the dependencies of a stored task and returns the branches
+// the task must be stacked on. Tasks that are not on the board have no known dependencies.
+func (a *App) resolveTaskDependencies(workspaceName string, taskID int, options TaskRunOptions) ([]string, []TaskBlocker, error) {
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load tasks: %v", err)
+	}
+
+	index := board.findTask(taskID)
+	if index < 0 || options.IgnoreDependencies {
+		return nil, nil, nil
+	}
+
+	var stackBranches []string
+	var remaining []TaskBlocker
+	for _, blocker := range findDependencyBlockers(board, board.Tasks[index]) {
+		if options.StackOnDependencies && isDependencyStackable(blocker) {
+			stackBranches = append(stackBranches, blocker.BranchName)
+			continue
+		}
+		if containsTaskID(options.SatisfiedDependencies, blocker.ID) {
+			continue
+		}
+		remaining = append(remaining, blocker)
+	}
+
+	return stackBranches, remaining, nil
+}
+
+// CheckTaskDependencies reports which dependencies keep a task from running
+func (a *App) CheckTaskDependencies(workspaceName string, taskID int) DependencyCheckResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return DependencyCheckResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return DependencyCheckResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load tasks: %v", err),
+		}
+	}
+
+	index := board.findTask(taskID)
+	if index < 0 {
+		return DependencyCheckResult{
+			Success: false,
+			Message: fmt.Sprintf("Task %d not found", taskID),
+		}
+	}
+
+	blockers := findDependencyBlockers(board, board.Tasks[index])
+	if len(blockers) == 0 {
+		return DependencyCheckResult{
+			Success: true,
+			Message: fmt.Sprintf("All dependencies of task %d are finished", taskID),
+			Ready:   true,
+		}
+	}
+
+	var stackable []string
+	for _, blocker := range blockers {
+		if isDependencyStackable(blocker) {
+			stackable = append(stackable, blocker.BranchName)
+		}
+	}
+
+	return DependencyCheckResult{
+		Success:           true,
+		Message:           fmt.Sprintf("Task %d is blocked by unfinished dependencies: %s", taskID, formatBlockers(blockers)),
+		Ready:             false,
+		BlockedBy:         blockers,
+		StackableBranches: stackable,
+	}
+}
+
+// mergeDependencyBranches merges the branches of unfinished dependencies into a freshly created task worktree
+func (a *App) mergeDependencyBranches(workspaceName, worktreePath string, branches []string) error {
+	if len(branches) == 0 {
+		return nil
+	}
+	setup, err := a.commitSetup(workspaceName, worktreePath)
+	if err != nil {
+		return err
+	}
+
+	for _, branch := range branches {
+		// Prefer the pushed branch; the local one may already have been cleaned up
+		ref := "origin/" + branch
+		verifyCmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref)
+		verifyCmd.Dir = worktreePath
+		if err := verifyCmd.Run(); err != nil {
+			ref = branch
+		}
+
+		cmd := setup.command(worktreePath, "merge", "--no-edit", "--no-ff", ref)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			abortCmd := exec.Command("git", "merge", "--abort")
+			abortCmd.Dir = worktreePath
+			abortCmd.Run() // Ignore errors - there may be nothing to abort
+
+			return fmt.Errorf("failed to merge dependency branch '%s': %v. Output: %s", branch, err, string(output))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunTaskBlockedByDependencies(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	// Stored directly, as SaveTasks refuses running tasks and missing dependencies
+	app.tasks.Update("demo", func(board *TaskBoard) error {
+		board.Tasks = []Task{
+			{ID: 1, Title: "Design schema", Status: TaskStatusDone},
+			{ID: 2, Title: "Build API", Status: TaskStatusRunning},
+			{ID: 3, Title: "Build UI", Status: TaskStatusTodo, Dependencies: []int{1, 2, 9}},
+		}
+		return nil
+	})
+
+	result := app.RunTask("demo", 3, "Build UI", "React frontend", "main")
+	if result.Success {
+		t.Fatal("Expected blocked task to be refused, got success")
+	}
+	if len(result.BlockedBy) != 2 {
+		t.Fatalf("Expected 2 blockers, got %+v", result.BlockedBy)
+	}
+	if result.BlockedBy[0].ID != 2 || result.BlockedBy[0].Status != TaskStatusRunning {
+		t.Errorf("Expected task 2 to block as running, got %+v", result.BlockedBy[0])
+	}
+	if result.BlockedBy[1].ID != 9 || !result.BlockedBy[1].Missing {
+		t.Errorf("Expected task 9 to block as missing, got %+v", result.BlockedBy[1])
+	}
+	if !strings.Contains(result.Message, "#2 (running), #9 (missing)") {
+		t.Errorf("Expected message to list the blockers, got: %s", result.Message)
+	}
+
+	// A refused run must not touch the task's status
+	list := app.ListTasks("demo")
+	if list.Tasks[2].Status != TaskStatusTodo {
+		t.Errorf("Expected blocked task to stay in todo, got %s", list.Tasks[2].Status)
+	}
+}
+
+func TestResolveTaskDependenciesStacksReviewBranches(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	app.SaveTasks("demo", []Task{
+		{ID: 1, Title: "Design schema", Status: TaskStatusReview, BranchName: "task-1-design-schema"},
+		{ID: 2, Title: "Build API", Dependencies: []int{1}},
+	})
+
+	check := app.CheckTaskDependencies("demo", 2)
+	if !check.Success || check.Ready {
+		t.Fatalf("Expected task 2 to be blocked, got %+v", check)
+	}
+	if len(check.StackableBranches) != 1 || check.StackableBranches[0] != "task-1-design-schema" {
+		t.Errorf("Expected the review branch to be offered for stacking, got %v", check.StackableBranches)
+	}
+
+	branches, blockers, err := app.resolveTaskDependencies("demo", 2, TaskRunOptions{StackOnDependencies: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(blockers) != 0 || len(branches) != 1 {
+		t.Errorf("Expected task 2 to be stacked on one branch, got branches %v and blockers %+v", branches, blockers)
+	}
+
+	_, blockers, _ = app.resolveTaskDependencies("demo", 2, TaskRunOptions{IgnoreDependencies: true})
+	if len(blockers) != 0 {
+		t.Errorf("Expected no blockers when ignoring dependencies, got %+v", blockers)
+	}
+}
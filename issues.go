@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"specprint/pkg/forge"
+)
+
+// IssuesSpecID tags the tasks imported from the issue tracker. It is not a valid spec name, so
+// generating tasks from a spec never replaces them.
+const IssuesSpecID = "@issues"
+
+// issueDependencyPattern matches references to the issues an issue depends on, e.g.
+// "Depends on #12" or "blocked by #7"
+var issueDependencyPattern = regexp.MustCompile(`(?i)\b(?:depends on|blocked by)\s+#(\d+)`)
+
+// IssueSyncResult represents the result of importing issues as tasks or exporting tasks as issues
+type IssueSyncResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Tasks   []Task `json:"tasks,omitempty"` // The tasks that were imported or exported
+}
+
+// labelPriority derives a task priority from issue labels such as "priority: high", "P1" or
+// "critical". Issues without a priority label are medium priority.
+func labelPriority(labels []string) string {
+	for _, label := range labels {
+		name := strings.ToLower(strings.TrimSpace(label))
+		for _, prefix := range []string{"priority::", "priority:", "priority/", "priority-", "prio:"} {
+			name = strings.TrimSpace(strings.TrimPrefix(name, prefix))
+		}
+		switch name {
+		case "high", "critical", "urgent", "p0", "p1":
+			return "high"
+		case "medium", "normal", "p2":
+			return "medium"
+		case "low", "minor", "p3", "p4":
+			return "low"
+		}
+	}
+	return "medium"
+}
+
+// issueDependencies returns the numbers of the issues an issue depends on: the ones the forge
+// links it as blocked by and the ones its body references
+func issueDependencies(issue forge.Issue) []int {
+	seen := map[int]bool{issue.Number: true}
+	var numbers []int
+	add := func(number int) {
+		if !seen[number] {
+			seen[number] = true
+			numbers = append(numbers, number)
+		}
+	}
+
+	for _, number := range issue.BlockedBy {
+		add(number)
+	}
+	for _, match := range issueDependencyPattern.FindAllStringSubmatch(issue.Body, -1) {
+		if number, err := strconv.Atoi(match[1]); err == nil {
+			add(number)
+		}
+	}
+	return numbers
+}
+
+// issueBody describes a task in an issue, with dependencies written so ImportIssues reads
+// them back
+func issueBody(task Task, dependencyIssues []int) string {
+	var body strings.Builder
+	body.WriteString(strings.TrimSpace(task.Description) + "\n")
+
+	if len(task.AcceptanceCriteria) > 0 {
+		body.WriteString("\n## Acceptance Criteria\n")
+		for _, criterion := range task.AcceptanceCriteria {
+			fmt.Fprintf(&body, "- [ ] %s\n", criterion)
+		}
+	}
+	if task.TestPlan != "" {
+		fmt.Fprintf(&body, "\n## Test Plan\n%s\n", task.TestPlan)
+	}
+	if task.Estimate != "" {
+		fmt.Fprintf(&body, "\nEstimate: %s\n", task.Estimate)
+	}
+	if len(dependencyIssues) > 0 {
+		body.WriteString("\n")
+		for _, number := range dependencyIssues {
+			fmt.Fprintf(&body, "Depends on #%d\n", number)
+		}
+	}
+	return body.String()
+}
+
+// dependenciesFirst orders tasks so that every task comes after the tasks it depends on,
+// keeping the original order otherwise. Tasks in a dependency cycle keep their order.
+func dependenciesFirst(tasks []Task) []Task {
+	byID := make(map[int]Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	var ordered []Task
+	visited := make(map[int]bool, len(tasks))
+	var visit func(task Task)
+	visit = func(task Task) {
+		if visited[task.ID] {
+			return
+		}
+		visited[task.ID] = true
+		for _, depID := range task.Dependencies {
+			if dep, ok := byID[depID]; ok {
+				visit(dep)
+			}
+		}
+		ordered = append(ordered, task)
+	}
+	for _, task := range tasks {
+		visit(task)
+	}
+	return ordered
+}
+
+// dropCycleDependencies removes the dependencies of imported tasks that close a dependency
+// cycle with each other or with the tasks already on the board, and returns how many it removed.
+// Issues that reference each other would otherwise keep every task of the board from running.
+func dropCycleDependencies(existing, imported []Task) int {
+	isImported := make(map[int]int, len(imported))
+	for i, task := range imported {
+		isImported[task.ID] = i
+	}
+
+	dropped := 0
+	for {
+		removed := false
+		for _, diagnostic := range validateTaskGraph(append(append([]Task{}, existing...), imported...)) {
+			if diagnostic.Kind != DiagnosticCycle {
+				continue
+			}
+			// Each task of the cycle depends on the next one; cut the link of the last imported task
+			cycle := diagnostic.Related
+			for i := len(cycle) - 1; i >= 0; i-- {
+				index, ok := isImported[cycle[i]]
+				if !ok {
+					continue
+				}
+				next := cycle[(i+1)%len(cycle)]
+				var dependencies []int
+				for _, depID := range imported[index].Dependencies {
+					if depID != next {
+						dependencies = append(dependencies, depID)
+					}
+				}
+				imported[index].Dependencies = dependencies
+				dropped++
+				removed = true
+				break
+			}
+			if removed {
+				// Cutting one link may break other cycles too, so check the graph again
+				break
+			}
+		}
+		if !removed {
+			return dropped
+		}
+	}
+}
+
+// ImportIssues adds the open issues of a workspace's repository that have all of the given
+// labels to its task board. Issues that were imported before are skipped.
+func (a *App) ImportIssues(workspaceName string, labels []string) IssueSyncResult {
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
+		return IssueSyncResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	client, err := a.workspaceForge(targetWorkspace)
+	if err != nil {
+		return IssueSyncResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), forgeRequestTimeout)
+	issues, err := client.ListIssues(ctx, labels)
+	cancel()
+	if err != nil {
+		return IssueSyncResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to list issues on %s: %v", client.Name(), err),
+		}
+	}
+
+	var imported []Task
+	skipped, dropped := 0, 0
+	_, err = a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		imported, skipped = nil, 0
+
+		// Dependencies may point at issues imported now or before
+		taskIDs := make(map[int]int)
+		for _, task := range board.Tasks {
+			if task.IssueNumber != 0 {
+				taskIDs[task.IssueNumber] = task.ID
+			}
+		}
+
+		var newIssues []forge.Issue
+		nextID := board.nextTaskID()
+		for _, issue := range issues {
+			if _, ok := taskIDs[issue.Number]; ok {
+				skipped++
+				continue
+			}
+			taskIDs[issue.Number] = nextID
+			nextID++
+			newIssues = append(newIssues, issue)
+		}
+
+		for _, issue := range newIssues {
+			description := strings.TrimSpace(issue.Body)
+			if description == "" {
+				description = issue.Title
+			}
+			task := Task{
+				ID:          taskIDs[issue.Number],
+				Title:       issue.Title,
+				Description: description,
+				Priority:    labelPriority(issue.Labels),
+				Spec:        IssuesSpecID,
+				IssueNumber: issue.Number,
+				IssueURL:    issue.URL,
+			}
+			for _, number := range issueDependencies(issue) {
+				if depID, ok := taskIDs[number]; ok {
+					task.Dependencies = append(task.Dependencies, depID)
+				}
+			}
+			normalizeTask(&task)
+			imported = append(imported, task)
+		}
+		dropped = dropCycleDependencies(board.Tasks, imported)
+		board.Tasks = append(board.Tasks, imported...)
+		return nil
+	})
+	if err != nil {
+		return IssueSyncResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save imported tasks: %v", err),
+		}
+	}
+
+	message := fmt.Sprintf("Imported %d issue(s) from %s as tasks; %d were already on the board", len(imported), client.Name(), skipped)
+	if dropped > 0 {
+		message += fmt.Sprintf("; dropped %d dependency link(s) that would have formed a cycle", dropped)
+	}
+	return IssueSyncResult{
+		Success: true,
+		Message: message,
+		Tasks:   imported,
+	}
+}
+
+// ExportTasksAsIssues opens an issue for each of the given tasks, or for every task if taskIDs
+// is empty, and records it on the task. Tasks that already have an issue are skipped. Each
+// issue gets the given labels and a "priority: <priority>" label.
+func (a *App) ExportTasksAsIssues(workspaceName string, taskIDs []int, labels []string) IssueSyncResult {
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
+		return IssueSyncResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return IssueSyncResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load tasks: %v", err),
+		}
+	}
+
+	selected := board.Tasks
+	if len(taskIDs) > 0 {
+		selected = nil
+		for _, taskID := range taskIDs {
+			index := board.findTask(taskID)
+			if index < 0 {
+				return IssueSyncResult{
+					Success: false,
+					Message: fmt.Sprintf("Task %d not found", taskID),
+				}
+			}
+			selected = append(selected, board.Tasks[index])
+		}
+	}
+
+	client, err := a.workspaceForge(targetWorkspace)
+	if err != nil {
+		return IssueSyncResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	// Open the issues of dependencies first, so dependent issues can reference them
+	issueNumbers := make(map[int]int)
+	for _, task := range board.Tasks {
+		if task.IssueNumber != 0 {
+			issueNumbers[task.ID] = task.IssueNumber
+		}
+	}
+
+	var exported []Task
+	var exportErr error
+	for _, task := range dependenciesFirst(selected) {
+		if task.IssueNumber != 0 {
+			continue
+		}
+
+		var dependencyIssues []int
+		for _, depID := range task.Dependencies {
+			if number, ok := issueNumbers[depID]; ok {
+				dependencyIssues = append(dependencyIssues, number)
+			}
+		}
+		issueLabels := append([]string{}, labels...)
+		if task.Priority != "" {
+			issueLabels = append(issueLabels, "priority: "+task.Priority)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), forgeRequestTimeout)
+		issue, err := client.CreateIssue(ctx, forge.NewIssue{
+			Title:  task.Title,
+			Body:   issueBody(task, dependencyIssues),
+			Labels: issueLabels,
+		})
+		cancel()
+		if err != nil {
+			exportErr = fmt.Errorf("failed to open an issue for task %d on %s: %v", task.ID, client.Name(), err)
+			break
+		}
+
+		issueNumbers[task.ID] = issue.Number
+		task.IssueNumber = issue.Number
+		task.IssueURL = issue.URL
+		exported = append(exported, task)
+	}
+
+	// Record the issues that were opened, even if a later one failed
+	if len(exported) > 0 {
+		_, err := a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+			for _, task := range exported {
+				if index := board.findTask(task.ID); index >= 0 {
+					board.Tasks[index].IssueNumber = task.IssueNumber
+					board.Tasks[index].IssueURL = task.IssueURL
+				}
+			}
+			return nil
+		})
+		if err != nil && exportErr == nil {
+			exportErr = fmt.Errorf("opened %d issue(s) but failed to record them on the tasks: %v", len(exported), err)
+		}
+	}
+
+	if exportErr != nil {
+		return IssueSyncResult{
+			Success: false,
+			Message: fmt.Sprintf("Exported %d task(s) before an error: %v", len(exported), exportErr),
+			Tasks:   exported,
+		}
+	}
+
+	return IssueSyncResult{
+		Success: true,
+		Message: fmt.Sprintf("Opened %d issue(s) on %s", len(exported), client.Name()),
+		Tasks:   exported,
+	}
+}
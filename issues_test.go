@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"specprint/pkg/forge"
+)
+
+func TestImportIssues(t *testing.T) {
+	app := setupForgeWorkspace(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/shop/issues" || r.URL.Query().Get("labels") != "backlog" {
+			t.Errorf("Unexpected request to %s", r.URL)
+		}
+		w.Write([]byte(`[
+			{"number":10,"html_url":"https://github.com/acme/shop/issues/10","title":"Add checkout","body":"Checkout flow\n\nDepends on #11 and blocked by #3","labels":[{"name":"backlog"},{"name":"priority: high"}]},
+			{"number":11,"html_url":"https://github.com/acme/shop/issues/11","title":"Add cart","body":"","labels":[{"name":"backlog"},{"name":"P3"}]},
+			{"number":3,"title":"Already imported","labels":[{"name":"backlog"}]}]`))
+	})
+	app.SaveTasks("demo", []Task{{ID: 1, Title: "Add login", Description: "Login", IssueNumber: 3}})
+
+	result := app.ImportIssues("demo", []string{"backlog"})
+	if !result.Success || len(result.Tasks) != 2 {
+		t.Fatalf("Expected two imported tasks, got %+v", result)
+	}
+
+	checkout, cart := result.Tasks[0], result.Tasks[1]
+	if checkout.ID != 2 || checkout.Priority != "high" || checkout.Spec != IssuesSpecID || checkout.IssueURL != "https://github.com/acme/shop/issues/10" {
+		t.Errorf("Unexpected checkout task: %+v", checkout)
+	}
+	if !reflect.DeepEqual(checkout.Dependencies, []int{3, 1}) {
+		t.Errorf("Expected checkout to depend on the cart and login tasks, got %v", checkout.Dependencies)
+	}
+	if cart.ID != 3 || cart.Priority != "low" || cart.Description != "Add cart" || cart.Status != TaskStatusTodo {
+		t.Errorf("Unexpected cart task: %+v", cart)
+	}
+
+	board, _ := app.tasks.Load("demo")
+	if len(board.Tasks) != 3 {
+		t.Errorf("Expected the imported tasks on the board, got %d tasks", len(board.Tasks))
+	}
+}
+
+func TestExportTasksAsIssues(t *testing.T) {
+	var created []map[string]interface{}
+	app := setupForgeWorkspace(t, func(w http.ResponseWriter, r *http.Request) {
+		var received map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&received)
+		created = append(created, received)
+		number := 40 + len(created)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"number":%d,"html_url":"https://github.com/acme/shop/issues/%d","title":%q}`, number, number, received["title"])
+	})
+	app.SaveTasks("demo", []Task{
+		{ID: 1, Title: "Add checkout", Description: "Checkout flow", Priority: "high", Dependencies: []int{2}, AcceptanceCriteria: []string{"Orders are stored"}},
+		{ID: 2, Title: "Add cart", Description: "Cart", Priority: "medium"},
+		{ID: 3, Title: "Exported", Description: "Done before", IssueNumber: 7},
+	})
+
+	result := app.ExportTasksAsIssues("demo", nil, []string{"specprint"})
+	if !result.Success || len(result.Tasks) != 2 || len(created) != 2 {
+		t.Fatalf("Expected two issues to be opened, got %+v", result)
+	}
+	if created[0]["title"] != "Add cart" || created[1]["title"] != "Add checkout" {
+		t.Errorf("Expected the dependency to be exported first, got %v", created)
+	}
+	if labels := created[1]["labels"]; !reflect.DeepEqual(labels, []interface{}{"specprint", "priority: high"}) {
+		t.Errorf("Unexpected labels: %v", labels)
+	}
+	body, _ := created[1]["body"].(string)
+	if !strings.Contains(body, "- [ ] Orders are stored") || !strings.Contains(body, "Depends on #41") {
+		t.Errorf("Expected criteria and the dependency's issue in the body, got:\n%s", body)
+	}
+
+	board, _ := app.tasks.Load("demo")
+	if board.Tasks[0].IssueNumber != 42 || board.Tasks[1].IssueNumber != 41 || board.Tasks[2].IssueNumber != 7 {
+		t.Errorf("Expected the issues to be recorded on the tasks, got %+v", board.Tasks)
+	}
+
+	// Importing the exported issues back finds them on the board
+	if deps := issueDependencies(forge.Issue{Number: 42, Body: body}); !reflect.DeepEqual(deps, []int{41}) {
+		t.Errorf("Expected the exported dependency to be read back, got %v", deps)
+	}
+}
+
+func TestLabelPriority(t *testing.T) {
+	tests := map[string][]string{
+		"high":   {"bug", "Priority::Critical"},
+		"medium": {"enhancement"},
+		"low":    {"priority/low"},
+	}
+	for expected, labels := range tests {
+		if priority := labelPriority(labels); priority != expected {
+			t.Errorf("Expected %v to be %s priority, got %s", labels, expected, priority)
+		}
+	}
+}
+
+func TestImportIssuesDropsDependencyCycles(t *testing.T) {
+	app := setupForgeWorkspace(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"number":20,"title":"Add orders","body":"Depends on #21"},
+			{"number":21,"title":"Add invoices","body":"Blocked by #20"}]`))
+	})
+
+	result := app.ImportIssues("demo", nil)
+	if !result.Success || len(result.Tasks) != 2 || !strings.Contains(result.Message, "dropped 1 dependency link(s)") {
+		t.Fatalf("Expected both issues to be imported without the cycle, got %+v", result)
+	}
+
+	board, _ := app.tasks.Load("demo")
+	if diagnostics := validateTaskGraph(board.Tasks); len(diagnostics) > 0 {
+		t.Errorf("Expected a valid task graph, got:\n%s", formatDiagnostics(diagnostics))
+	}
+	if len(board.Tasks[0].Dependencies)+len(board.Tasks[1].Dependencies) != 1 {
+		t.Errorf("Expected one of the two links to be kept, got %v and %v", board.Tasks[0].Dependencies, board.Tasks[1].Dependencies)
+	}
+}
@@ -10,15 +10,39 @@ import (
 
 // TaskExecutionResult represents the result of executing a task with Claude
 type TaskExecutionResult struct {
-	Success      bool     `json:"success"`
-	Message      string   `json:"message"`
-	SessionID    string   `json:"sessionId,omitempty"`
-	FilesChanged []string `json:"filesChanged,omitempty"`
+	Success         bool             `json:"success"`
+	Message         string           `json:"message"`
+	SessionID       string           `json:"sessionId,omitempty"`
+	FilesChanged    []string         `json:"filesChanged,omitempty"`
+	Prompt          string           `json:"prompt,omitempty"`          // The prompt the session was started or continued with
+	Transcript      string           `json:"transcript,omitempty"`      // Everything Claude wrote during the session
+	Summary         string           `json:"summary,omitempty"`         // The final result Claude reported
+	Usage           *Usage           `json:"usage,omitempty"`           // Tokens and cost, if Claude reported them
+	ToolInvocations []ToolInvocation `json:"toolInvocations,omitempty"` // Tools Claude used, in order
+	Config          RunConfig        `json:"config"`                    // The effective config the session ran with
+}
+
+// Usage reports the resources a Claude session consumed
+type Usage struct {
+	InputTokens  int     `json:"inputTokens"`
+	OutputTokens int     `json:"outputTokens"`
+	CostUSD      float64 `json:"costUsd"`
+	NumTurns     int     `json:"numTurns"`
+	DurationMs   int     `json:"durationMs"`
+}
+
+// ToolInvocation records a tool Claude used during a session
+type ToolInvocation struct {
+	Name     string                 `json:"name"`
+	Input    map[string]interface{} `json:"input,omitempty"`
+	FilePath string                 `json:"filePath,omitempty"` // The file the tool read or changed, if any
 }
 
 // ClaudeClient wraps the Claude Code SDK for task execution
 type ClaudeClient struct {
 	workingDirectory string
+	streamHandler    StreamHandler
+	runConfig        RunConfig
 }
 
 // NewClaudeClient creates a new Claude client with the specified working directory
@@ -28,185 +52,155 @@ func NewClaudeClient(workingDirectory string) *ClaudeClient {
 	}
 }
 
+// SetStreamHandler registers a handler that receives every assistant message, tool use and
+// file edit while a task or conversation is running
+func (c *ClaudeClient) SetStreamHandler(handler StreamHandler) {
+	c.streamHandler = handler
+}
+
+// SetRunConfig overrides the default run config of tasks and conversations. Fields that are
+// not set keep their defaults.
+func (c *ClaudeClient) SetRunConfig(config RunConfig) {
+	c.runConfig = config
+}
+
 // ContinueConversation continues an existing conversation using sessionId
 func (c *ClaudeClient) ContinueConversation(sessionId, userMessage string) TaskExecutionResult {
-	ctx := context.Background()
+	return c.ContinueConversationContext(context.Background(), sessionId, userMessage)
+}
+
+// ContinueConversationContext continues an existing conversation; cancelling ctx stops the Claude process
+func (c *ClaudeClient) ContinueConversationContext(ctx context.Context, sessionId, userMessage string) TaskExecutionResult {
+	config := DefaultConversationRunConfig().Merge(c.runConfig)
 
 	// Create the request to continue the conversation
+	options := config.options(c.workingDirectory)
+	options.Resume = stringPtr(sessionId) // Resume existing session using correct field
 	request := claudecode.QueryRequest{
-		Prompt: userMessage,
-		Options: &claudecode.Options{
-			MaxTurns:       intPtr(10),
-			AllowedTools:   []string{"Read", "Write", "LS", "Grep", "Edit"},
-			SystemPrompt:   stringPtr("You are a senior software engineer helping to implement and modify development tasks. Focus on understanding the user's request and making the appropriate changes while maintaining code quality."),
-			Cwd:            &c.workingDirectory,
-			Verbose:        boolPtr(true),
-			PermissionMode: stringPtr("acceptEdits"),
-			Resume:         stringPtr(sessionId), // Resume existing session using correct field
-		},
+		Prompt:  userMessage,
+		Options: options,
 	}
 
-	// Execute the request
-	messages, err := claudecode.QueryWithRequest(ctx, request)
+	// Execute the request, streaming progress to the handler
+	collected, err := c.runQuery(ctx, config, request)
+	result := collected.result(config)
+	result.SessionID = sessionId // Return the same session ID
+	result.Prompt = userMessage
 	if err != nil {
-		return TaskExecutionResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to continue conversation with Claude: %v", err),
-		}
+		result.Message = fmt.Sprintf("Failed to continue conversation with Claude: %v", err)
+		return result
 	}
 
-	if len(messages) == 0 {
-		return TaskExecutionResult{
-			Success: false,
-			Message: "No response received from Claude",
-		}
-	}
-
-	// Extract response and track file changes
-	var filesChanged []string
-	responseContent := []string{}
-
-	for _, message := range messages {
-		switch msg := message.(type) {
-		case *claudecode.ResultMessage:
-			for _, block := range msg.Content() {
-				if textBlock, ok := block.(*claudecode.TextBlock); ok {
-					responseContent = append(responseContent, textBlock.Text)
-				}
-			}
-		case *claudecode.AssistantMessage:
-			for _, block := range msg.Content() {
-				if textBlock, ok := block.(*claudecode.TextBlock); ok {
-					responseContent = append(responseContent, textBlock.Text)
-				}
-				if toolBlock, ok := block.(*claudecode.ToolUseBlock); ok {
-					// Track file operations
-					if toolBlock.Name == "Write" || toolBlock.Name == "Edit" {
-						if path, exists := toolBlock.Input["path"]; exists {
-							if pathStr, ok := path.(string); ok {
-								filesChanged = append(filesChanged, pathStr)
-							}
-						}
-					}
-				}
-			}
-		}
+	if collected.messageCount == 0 {
+		result.Message = "No response received from Claude"
+		return result
 	}
 
-	response := strings.Join(responseContent, "\n")
-
-	return TaskExecutionResult{
-		Success:      true,
-		Message:      response,
-		SessionID:    sessionId, // Return the same session ID
-		FilesChanged: removeDuplicates(filesChanged),
-	}
+	result.Success = true
+	result.Message = strings.Join(collected.responseContent, "\n")
+	return result
 }
 
-// extractSessionIDFromResult attempts to extract session ID from a result message
-func (c *ClaudeClient) extractSessionIDFromResult(result *claudecode.ResultMessage) string {
-	// The session ID is available directly from the ResultMessage
-	if result != nil {
-		return result.SessionID
-	}
-	return ""
+// TaskBrief describes a task for Claude to implement
+type TaskBrief struct {
+	ID                 int
+	Title              string
+	Description        string
+	AcceptanceCriteria []string // Conditions that must hold for the task to be done
+	Files              []string // Files the task is likely to touch
+	TestPlan           string   // How to verify the task
 }
 
-// ExecuteTask runs a task using Claude Code CLI
-func (c *ClaudeClient) ExecuteTask(taskID int, taskTitle, taskDescription string) TaskExecutionResult {
-	ctx := context.Background()
-
-	// Construct a detailed prompt for Claude
-	prompt := fmt.Sprintf(`I need help implementing this specific task:
+// Prompt builds the prompt a task session is started with
+func (b TaskBrief) Prompt() string {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, `I need help implementing this specific task:
 
 **Task ID**: %d
 **Title**: %s
 **Description**: %s
+`, b.ID, b.Title, b.Description)
 
+	if len(b.AcceptanceCriteria) > 0 {
+		prompt.WriteString("\n**Acceptance Criteria**:\n")
+		for _, criterion := range b.AcceptanceCriteria {
+			fmt.Fprintf(&prompt, "- %s\n", criterion)
+		}
+	}
+	if len(b.Files) > 0 {
+		fmt.Fprintf(&prompt, "\n**Files Likely Touched**: %s\n", strings.Join(b.Files, ", "))
+	}
+	if b.TestPlan != "" {
+		fmt.Fprintf(&prompt, "\n**Test Plan**: %s\n", b.TestPlan)
+	}
+
+	prompt.WriteString(`
 Please analyze the current codebase and implement this task. Consider:
 1. The existing code structure and patterns
 2. Best practices for the technology stack being used
 3. Any dependencies or integration points
 4. Testing requirements if applicable
 
-Please implement the necessary code changes to complete this task.`,
-		taskID, taskTitle, taskDescription)
+Please implement the necessary code changes to complete this task.`)
+
+	if len(b.AcceptanceCriteria) > 0 || b.TestPlan != "" {
+		prompt.WriteString(" The task is done when every acceptance criterion holds and the test plan passes; before finishing, check each criterion and say which are met.")
+	}
+
+	return prompt.String()
+}
+
+// ExecuteTask runs a task using Claude Code CLI
+func (c *ClaudeClient) ExecuteTask(taskID int, taskTitle, taskDescription string) TaskExecutionResult {
+	return c.ExecuteTaskContext(context.Background(), taskID, taskTitle, taskDescription)
+}
+
+// ExecuteTaskContext runs a task using Claude Code CLI; cancelling ctx stops the Claude process
+func (c *ClaudeClient) ExecuteTaskContext(ctx context.Context, taskID int, taskTitle, taskDescription string) TaskExecutionResult {
+	return c.ExecuteTaskBrief(ctx, TaskBrief{ID: taskID, Title: taskTitle, Description: taskDescription})
+}
+
+// ExecuteTaskBrief runs a task using Claude Code CLI, telling Claude the task's acceptance
+// criteria, likely files and test plan; cancelling ctx stops the Claude process
+func (c *ClaudeClient) ExecuteTaskBrief(ctx context.Context, brief TaskBrief) TaskExecutionResult {
+	prompt := brief.Prompt()
+
+	config := DefaultTaskRunConfig().Merge(c.runConfig)
 
 	// Create the request using the TypeScript/Python compatible API
 	request := claudecode.QueryRequest{
-		Prompt: prompt,
-		Options: &claudecode.Options{
-			MaxTurns:       intPtr(10),
-			AllowedTools:   []string{"Read", "Write", "LS", "Grep"},
-			SystemPrompt:   stringPtr("You are a senior software engineer helping to implement specific development tasks. Focus on writing high-quality, maintainable code that follows the existing codebase patterns."),
-			Cwd:            &c.workingDirectory,
-			Verbose:        boolPtr(true),
-			PermissionMode: stringPtr("acceptEdits"),
-		},
+		Prompt:  prompt,
+		Options: config.options(c.workingDirectory),
 	}
 
-	// Execute the request
-	messages, err := claudecode.QueryWithRequest(ctx, request)
+	// Execute the request, streaming progress to the handler
+	collected, err := c.runQuery(ctx, config, request)
+	result := collected.result(config)
+	result.Prompt = prompt
 	if err != nil {
-		return TaskExecutionResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to execute task with Claude: %v", err),
-		}
+		result.Message = fmt.Sprintf("Failed to execute task with Claude: %v", err)
+		return result
 	}
 
-	if len(messages) == 0 {
-		return TaskExecutionResult{
-			Success: false,
-			Message: "No response received from Claude",
-		}
+	if collected.messageCount == 0 {
+		result.Message = "No response received from Claude"
+		return result
 	}
 
-	// Extract session ID and analyze the response
-	var sessionID string
-	var filesChanged []string
-	responseContent := []string{}
-
-	for _, message := range messages {
-		switch msg := message.(type) {
-		case *claudecode.ResultMessage:
-			// Try to extract session ID from result if available
-			sessionID = c.extractSessionIDFromResult(msg)
-			for _, block := range msg.Content() {
-				if textBlock, ok := block.(*claudecode.TextBlock); ok {
-					responseContent = append(responseContent, textBlock.Text)
-				}
-			}
-		case *claudecode.AssistantMessage:
-			for _, block := range msg.Content() {
-				if textBlock, ok := block.(*claudecode.TextBlock); ok {
-					responseContent = append(responseContent, textBlock.Text)
-				}
-				if toolBlock, ok := block.(*claudecode.ToolUseBlock); ok {
-					// Track file operations
-					if toolBlock.Name == "Write" || toolBlock.Name == "Edit" {
-						if path, exists := toolBlock.Input["path"]; exists {
-							if pathStr, ok := path.(string); ok {
-								filesChanged = append(filesChanged, pathStr)
-							}
-						}
-					}
-				}
-			}
-		}
+	// Report what Claude said it did, falling back to the transcript if it gave no summary
+	result.Success = true
+	result.Message = result.Summary
+	if result.Message == "" {
+		result.Message = result.Transcript
 	}
-
-	// Join all response content (for potential future use)
-	_ = strings.Join(responseContent, "\n")
-
-	return TaskExecutionResult{
-		Success:      true,
-		Message:      fmt.Sprintf("Successfully executed task %d. Claude processed %d messages.", taskID, len(messages)),
-		SessionID:    sessionID,
-		FilesChanged: removeDuplicates(filesChanged),
+	if result.Message == "" {
+		result.Message = fmt.Sprintf("Successfully executed task %d", brief.ID)
 	}
+	return result
 }
 
-// ExecuteTaskWithStreaming runs a task using Claude Code CLI with streaming
+// ExecuteTaskWithStreaming runs a task in the background. Progress is delivered to the stream
+// handler; the result or error is sent on the returned channels once the session ends.
 func (c *ClaudeClient) ExecuteTaskWithStreaming(taskID int, taskTitle, taskDescription string) (chan TaskExecutionResult, chan error) {
 	resultChan := make(chan TaskExecutionResult, 1)
 	errorChan := make(chan error, 1)
@@ -215,84 +209,12 @@ func (c *ClaudeClient) ExecuteTaskWithStreaming(taskID int, taskTitle, taskDescr
 		defer close(resultChan)
 		defer close(errorChan)
 
-		ctx := context.Background()
-
-		// Construct a detailed prompt for Claude
-		prompt := fmt.Sprintf(`I need help implementing this specific task:
-
-**Task ID**: %d
-**Title**: %s
-**Description**: %s
-
-Please analyze the current codebase and implement this task step by step. Consider:
-1. The existing code structure and patterns
-2. Best practices for the technology stack being used
-3. Any dependencies or integration points
-4. Testing requirements if applicable
-
-Please implement the necessary code changes to complete this task.`,
-			taskID, taskTitle, taskDescription)
-
-		// Create the streaming request
-		request := claudecode.QueryRequest{
-			Prompt: prompt,
-			Options: &claudecode.Options{
-				MaxTurns:     intPtr(5),
-				AllowedTools: []string{"Read", "Write", "LS", "Grep", "Edit"},
-				SystemPrompt: stringPtr("You are a senior software engineer helping to implement specific development tasks. Focus on writing high-quality, maintainable code that follows the existing codebase patterns."),
-				Cwd:          &c.workingDirectory,
-				OutputFormat: outputFormatPtr(claudecode.OutputFormatStreamJSON),
-				Verbose:      boolPtr(true),
-			},
-		}
-
-		// Execute the streaming request
-		messageChan, errChan := claudecode.QueryStreamWithRequest(ctx, request)
-
-		var filesChanged []string
-		messageCount := 0
-
-		for {
-			select {
-			case message, ok := <-messageChan:
-				if !ok {
-					// Streaming completed
-					resultChan <- TaskExecutionResult{
-						Success:      true,
-						Message:      fmt.Sprintf("Successfully executed task %d with streaming. Processed %d messages.", taskID, messageCount),
-						FilesChanged: removeDuplicates(filesChanged),
-					}
-					return
-				}
-
-				messageCount++
-
-				// Track file operations from tool use blocks
-				if assistantMsg, ok := message.(*claudecode.AssistantMessage); ok {
-					for _, block := range assistantMsg.Content() {
-						if toolBlock, ok := block.(*claudecode.ToolUseBlock); ok {
-							if toolBlock.Name == "Write" || toolBlock.Name == "Edit" {
-								if path, exists := toolBlock.Input["path"]; exists {
-									if pathStr, ok := path.(string); ok {
-										filesChanged = append(filesChanged, pathStr)
-									}
-								}
-							}
-						}
-					}
-				}
-
-			case err := <-errChan:
-				if err != nil {
-					errorChan <- fmt.Errorf("streaming error during task execution: %v", err)
-					return
-				}
-
-			case <-ctx.Done():
-				errorChan <- fmt.Errorf("context cancelled during task execution")
-				return
-			}
+		result := c.ExecuteTask(taskID, taskTitle, taskDescription)
+		if !result.Success {
+			errorChan <- fmt.Errorf("%s", result.Message)
+			return
 		}
+		resultChan <- result
 	}()
 
 	return resultChan, errorChan
@@ -311,10 +233,6 @@ func boolPtr(b bool) *bool {
 	return &b
 }
 
-func outputFormatPtr(f claudecode.OutputFormat) *claudecode.OutputFormat {
-	return &f
-}
-
 // removeDuplicates removes duplicate strings from a slice
 func removeDuplicates(slice []string) []string {
 	seen := make(map[string]bool)
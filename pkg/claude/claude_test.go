@@ -0,0 +1,35 @@
+package claude
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaskBriefPrompt(t *testing.T) {
+	brief := TaskBrief{
+		ID:                 3,
+		Title:              "Add login",
+		Description:        "Create the login endpoint",
+		AcceptanceCriteria: []string{"Valid credentials return a token", "Invalid credentials return 401"},
+		Files:              []string{"api/auth.go", "api/auth_test.go"},
+		TestPlan:           "Run go test ./api/...",
+	}
+
+	prompt := brief.Prompt()
+	for _, expected := range []string{
+		"**Title**: Add login",
+		"**Acceptance Criteria**:\n- Valid credentials return a token\n- Invalid credentials return 401\n",
+		"**Files Likely Touched**: api/auth.go, api/auth_test.go",
+		"**Test Plan**: Run go test ./api/...",
+		"check each criterion",
+	} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected %q in the prompt, got:\n%s", expected, prompt)
+		}
+	}
+
+	plain := TaskBrief{ID: 1, Title: "Set up", Description: "Init"}.Prompt()
+	if strings.Contains(plain, "Acceptance Criteria") || strings.Contains(plain, "check each criterion") {
+		t.Errorf("Expected no criteria section for a task without criteria, got:\n%s", plain)
+	}
+}
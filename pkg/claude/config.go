@@ -0,0 +1,104 @@
+package claude
+
+import (
+	"fmt"
+	"time"
+
+	claudecode "github.com/yukifoo/claude-code-sdk-go"
+)
+
+// Permission modes accepted by the Claude Code CLI
+var permissionModes = map[string]bool{
+	"default":           true,
+	"acceptEdits":       true,
+	"bypassPermissions": true,
+	"plan":              true,
+}
+
+// RunConfig controls how a Claude session runs. Zero values mean "not set", so configs from
+// several levels (global, workspace, task) can be layered with Merge.
+type RunConfig struct {
+	MaxTurns       int      `json:"maxTurns,omitempty"`
+	TimeoutSeconds int      `json:"timeoutSeconds,omitempty"` // Wall-clock limit for the whole session
+	AllowedTools   []string `json:"allowedTools,omitempty"`
+	SystemPrompt   string   `json:"systemPrompt,omitempty"`
+	PermissionMode string   `json:"permissionMode,omitempty"`
+}
+
+// DefaultTaskRunConfig returns the config used to implement a new task
+func DefaultTaskRunConfig() RunConfig {
+	return RunConfig{
+		MaxTurns:       10,
+		AllowedTools:   []string{"Read", "Write", "LS", "Grep"},
+		SystemPrompt:   "You are a senior software engineer helping to implement specific development tasks. Focus on writing high-quality, maintainable code that follows the existing codebase patterns.",
+		PermissionMode: "acceptEdits",
+	}
+}
+
+// DefaultConversationRunConfig returns the config used to continue a conversation
+func DefaultConversationRunConfig() RunConfig {
+	return RunConfig{
+		MaxTurns:       10,
+		AllowedTools:   []string{"Read", "Write", "LS", "Grep", "Edit"},
+		SystemPrompt:   "You are a senior software engineer helping to implement and modify development tasks. Focus on understanding the user's request and making the appropriate changes while maintaining code quality.",
+		PermissionMode: "acceptEdits",
+	}
+}
+
+// Merge returns a copy of c with every field that is set in override replaced
+func (c RunConfig) Merge(override RunConfig) RunConfig {
+	if override.MaxTurns > 0 {
+		c.MaxTurns = override.MaxTurns
+	}
+	if override.TimeoutSeconds > 0 {
+		c.TimeoutSeconds = override.TimeoutSeconds
+	}
+	if len(override.AllowedTools) > 0 {
+		c.AllowedTools = append([]string(nil), override.AllowedTools...)
+	}
+	if override.SystemPrompt != "" {
+		c.SystemPrompt = override.SystemPrompt
+	}
+	if override.PermissionMode != "" {
+		c.PermissionMode = override.PermissionMode
+	}
+	return c
+}
+
+// Validate checks that the config only contains values the Claude Code CLI accepts
+func (c RunConfig) Validate() error {
+	if c.MaxTurns < 0 {
+		return fmt.Errorf("max turns cannot be negative")
+	}
+	if c.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	if c.PermissionMode != "" && !permissionModes[c.PermissionMode] {
+		return fmt.Errorf("unknown permission mode: %s", c.PermissionMode)
+	}
+	return nil
+}
+
+// Timeout returns the wall-clock limit of a session, or 0 if there is none
+func (c RunConfig) Timeout() time.Duration {
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// options converts the config into SDK options for a session in the given directory
+func (c RunConfig) options(workingDirectory string) *claudecode.Options {
+	options := &claudecode.Options{
+		AllowedTools: c.AllowedTools,
+		Cwd:          &workingDirectory,
+		Verbose:      boolPtr(true),
+	}
+	if c.MaxTurns > 0 {
+		options.MaxTurns = intPtr(c.MaxTurns)
+	}
+	if c.SystemPrompt != "" {
+		options.SystemPrompt = stringPtr(c.SystemPrompt)
+	}
+	if c.PermissionMode != "" {
+		options.PermissionMode = stringPtr(c.PermissionMode)
+	}
+	return options
+}
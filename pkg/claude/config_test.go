@@ -0,0 +1,41 @@
+package claude
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRunConfigMerge(t *testing.T) {
+	base := DefaultTaskRunConfig()
+
+	merged := base.Merge(RunConfig{MaxTurns: 25, TimeoutSeconds: 600, AllowedTools: []string{"Read", "Edit"}})
+	if merged.MaxTurns != 25 || merged.Timeout() != 10*time.Minute {
+		t.Errorf("Expected turns and timeout to be overridden, got %+v", merged)
+	}
+	if !reflect.DeepEqual(merged.AllowedTools, []string{"Read", "Edit"}) {
+		t.Errorf("Expected allowed tools to be overridden, got %v", merged.AllowedTools)
+	}
+	if merged.SystemPrompt != base.SystemPrompt || merged.PermissionMode != base.PermissionMode {
+		t.Errorf("Expected unset fields to keep their defaults, got %+v", merged)
+	}
+
+	if unchanged := base.Merge(RunConfig{}); !reflect.DeepEqual(unchanged, base) {
+		t.Errorf("Expected merging an empty config to change nothing, got %+v", unchanged)
+	}
+}
+
+func TestRunConfigValidate(t *testing.T) {
+	if err := (RunConfig{MaxTurns: 5, PermissionMode: "plan"}).Validate(); err != nil {
+		t.Errorf("Expected config to be valid, got: %v", err)
+	}
+	if err := (RunConfig{MaxTurns: -1}).Validate(); err == nil {
+		t.Error("Expected negative max turns to be rejected")
+	}
+	if err := (RunConfig{TimeoutSeconds: -5}).Validate(); err == nil {
+		t.Error("Expected negative timeout to be rejected")
+	}
+	if err := (RunConfig{PermissionMode: "yolo"}).Validate(); err == nil {
+		t.Error("Expected unknown permission mode to be rejected")
+	}
+}
@@ -0,0 +1,167 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	claudecode "github.com/yukifoo/claude-code-sdk-go"
+)
+
+// Types of StreamEvent
+const (
+	StreamEventAssistant = "assistant" // Text written by Claude
+	StreamEventToolUse   = "tool_use"  // Claude invoked a tool
+	StreamEventFileEdit  = "file_edit" // A tool invocation that writes a file
+	StreamEventResult    = "result"    // The final result of the session
+)
+
+// StreamEvent describes one step of a running Claude session
+type StreamEvent struct {
+	Type      string                 `json:"type"`
+	SessionID string                 `json:"sessionId,omitempty"`
+	Text      string                 `json:"text,omitempty"`
+	ToolName  string                 `json:"toolName,omitempty"`
+	ToolInput map[string]interface{} `json:"toolInput,omitempty"`
+	FilePath  string                 `json:"filePath,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// StreamHandler receives the events of a running Claude session
+type StreamHandler func(event StreamEvent)
+
+// fileEditTools are the tools whose invocations change files in the working directory
+var fileEditTools = map[string]bool{
+	"Write":     true,
+	"Edit":      true,
+	"MultiEdit": true,
+}
+
+// queryCollector accumulates the outcome of a Claude session while its messages stream in
+type queryCollector struct {
+	handler         StreamHandler
+	sessionID       string
+	responseContent []string // Assistant text and the final result, in order
+	transcript      []string // Assistant text only
+	summary         string
+	usage           *Usage
+	toolInvocations []ToolInvocation
+	filesChanged    []string
+	messageCount    int
+}
+
+// handle records a message and forwards it to the stream handler
+func (q *queryCollector) handle(message claudecode.Message) {
+	q.messageCount++
+
+	switch msg := message.(type) {
+	case *claudecode.AssistantMessage:
+		if msg.SessionID != "" {
+			q.sessionID = msg.SessionID
+		}
+		for _, block := range msg.Content() {
+			switch b := block.(type) {
+			case *claudecode.TextBlock:
+				q.responseContent = append(q.responseContent, b.Text)
+				q.transcript = append(q.transcript, b.Text)
+				q.emit(StreamEvent{Type: StreamEventAssistant, Text: b.Text})
+			case *claudecode.ToolUseBlock:
+				q.toolInvocations = append(q.toolInvocations, ToolInvocation{Name: b.Name, Input: b.Input, FilePath: toolFilePath(b)})
+				q.emit(StreamEvent{Type: StreamEventToolUse, ToolName: b.Name, ToolInput: b.Input})
+
+				// Track file operations
+				if fileEditTools[b.Name] {
+					if path := toolFilePath(b); path != "" {
+						q.filesChanged = append(q.filesChanged, path)
+						q.emit(StreamEvent{Type: StreamEventFileEdit, ToolName: b.Name, FilePath: path})
+					}
+				}
+			}
+		}
+	case *claudecode.ResultMessage:
+		if msg.SessionID != "" {
+			q.sessionID = msg.SessionID
+		}
+		for _, block := range msg.Content() {
+			if textBlock, ok := block.(*claudecode.TextBlock); ok {
+				q.responseContent = append(q.responseContent, textBlock.Text)
+				q.summary = textBlock.Text
+			}
+		}
+
+		q.usage = &Usage{NumTurns: msg.NumTurns, DurationMs: msg.DurationMs}
+		if msg.Usage != nil {
+			q.usage.InputTokens = msg.Usage.InputTokens
+			q.usage.OutputTokens = msg.Usage.OutputTokens
+		}
+		if msg.TotalCostUSD != nil {
+			q.usage.CostUSD = *msg.TotalCostUSD
+		}
+
+		q.emit(StreamEvent{Type: StreamEventResult, Text: q.summary})
+	}
+}
+
+// result returns what was collected as an unsuccessful result; callers set Success and Message
+func (q *queryCollector) result(config RunConfig) TaskExecutionResult {
+	return TaskExecutionResult{
+		SessionID:       q.sessionID,
+		FilesChanged:    removeDuplicates(q.filesChanged),
+		Transcript:      strings.Join(q.transcript, "\n\n"),
+		Summary:         q.summary,
+		Usage:           q.usage,
+		ToolInvocations: q.toolInvocations,
+		Config:          config,
+	}
+}
+
+// emit sends an event to the stream handler, if any
+func (q *queryCollector) emit(event StreamEvent) {
+	if q.handler == nil {
+		return
+	}
+	event.SessionID = q.sessionID
+	event.Timestamp = time.Now()
+	q.handler(event)
+}
+
+// toolFilePath returns the file a tool invocation operates on. The CLI passes it as
+// "file_path"; "path" is accepted as well for older versions.
+func toolFilePath(block *claudecode.ToolUseBlock) string {
+	for _, key := range []string{"file_path", "path"} {
+		if path, ok := block.Input[key].(string); ok && path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// runQuery executes a request in streaming mode, forwarding every message to the client's
+// stream handler as it arrives, and returns the collected outcome once the session ends.
+// The session is stopped when it exceeds the config's timeout.
+func (c *ClaudeClient) runQuery(ctx context.Context, config RunConfig, request claudecode.QueryRequest) (*queryCollector, error) {
+	collector := &queryCollector{handler: c.streamHandler}
+
+	queryCtx := ctx
+	if timeout := config.Timeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		queryCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	messageChan, errChan := claudecode.QueryStreamWithRequest(queryCtx, request)
+	for message := range messageChan {
+		collector.handle(message)
+	}
+
+	err := <-errChan
+	if ctx.Err() == nil && queryCtx.Err() == context.DeadlineExceeded {
+		return collector, fmt.Errorf("session timed out after %s", config.Timeout())
+	}
+	if err != nil {
+		return collector, fmt.Errorf("streaming error: %v", err)
+	}
+
+	return collector, nil
+}
@@ -0,0 +1,115 @@
+package claude
+
+import (
+	"reflect"
+	"testing"
+
+	claudecode "github.com/yukifoo/claude-code-sdk-go"
+)
+
+func TestQueryCollectorForwardsEvents(t *testing.T) {
+	var events []StreamEvent
+	collector := &queryCollector{handler: func(event StreamEvent) {
+		events = append(events, event)
+	}}
+
+	result := "Added the login form"
+	messages := []claudecode.Message{
+		&claudecode.SystemMessage{Subtype: "init", SessionID: "session-1"},
+		&claudecode.AssistantMessage{
+			SessionID: "session-1",
+			ContentBlocks: []claudecode.ContentBlock{
+				&claudecode.TextBlock{Text: "Let me look at the form."},
+				&claudecode.ToolUseBlock{Name: "Read", Input: map[string]interface{}{"file_path": "src/form.tsx"}},
+				&claudecode.ToolUseBlock{Name: "Edit", Input: map[string]interface{}{"file_path": "src/form.tsx"}},
+				&claudecode.ToolUseBlock{Name: "Write", Input: map[string]interface{}{"path": "src/login.tsx"}},
+			},
+		},
+		&claudecode.ResultMessage{SessionID: "session-1", Result: &result},
+	}
+	for _, message := range messages {
+		collector.handle(message)
+	}
+
+	var types []string
+	for _, event := range events {
+		types = append(types, event.Type)
+		if event.SessionID != "session-1" {
+			t.Errorf("Expected session ID on %s event, got %q", event.Type, event.SessionID)
+		}
+	}
+	expected := []string{
+		StreamEventAssistant,
+		StreamEventToolUse,
+		StreamEventToolUse, StreamEventFileEdit,
+		StreamEventToolUse, StreamEventFileEdit,
+		StreamEventResult,
+	}
+	if !reflect.DeepEqual(types, expected) {
+		t.Errorf("Expected events %v, got %v", expected, types)
+	}
+
+	if collector.messageCount != 3 {
+		t.Errorf("Expected 3 messages, got %d", collector.messageCount)
+	}
+	if collector.sessionID != "session-1" {
+		t.Errorf("Expected session ID session-1, got %q", collector.sessionID)
+	}
+	if !reflect.DeepEqual(collector.filesChanged, []string{"src/form.tsx", "src/login.tsx"}) {
+		t.Errorf("Expected changed files to be tracked, got %v", collector.filesChanged)
+	}
+	if events[len(events)-1].Text != result {
+		t.Errorf("Expected result event to carry the result, got %q", events[len(events)-1].Text)
+	}
+}
+
+func TestQueryCollectorResult(t *testing.T) {
+	collector := &queryCollector{}
+
+	result := "Added a search endpoint and tests."
+	cost := 0.42
+	messages := []claudecode.Message{
+		&claudecode.AssistantMessage{
+			SessionID: "session-2",
+			ContentBlocks: []claudecode.ContentBlock{
+				&claudecode.TextBlock{Text: "I'll start with the handler."},
+				&claudecode.ToolUseBlock{Name: "Grep", Input: map[string]interface{}{"pattern": "func Search"}},
+				&claudecode.ToolUseBlock{Name: "Write", Input: map[string]interface{}{"file_path": "search.go"}},
+			},
+		},
+		&claudecode.AssistantMessage{
+			SessionID:     "session-2",
+			ContentBlocks: []claudecode.ContentBlock{&claudecode.TextBlock{Text: "Now the tests."}},
+		},
+		&claudecode.ResultMessage{
+			SessionID:    "session-2",
+			Result:       &result,
+			NumTurns:     4,
+			DurationMs:   5200,
+			TotalCostUSD: &cost,
+			Usage:        &claudecode.Usage{InputTokens: 1200, OutputTokens: 340},
+		},
+	}
+	for _, message := range messages {
+		collector.handle(message)
+	}
+
+	collected := collector.result(DefaultTaskRunConfig())
+
+	if collected.Transcript != "I'll start with the handler.\n\nNow the tests." {
+		t.Errorf("Expected the assistant transcript, got %q", collected.Transcript)
+	}
+	if collected.Summary != result {
+		t.Errorf("Expected the result summary, got %q", collected.Summary)
+	}
+	expectedUsage := &Usage{InputTokens: 1200, OutputTokens: 340, CostUSD: 0.42, NumTurns: 4, DurationMs: 5200}
+	if !reflect.DeepEqual(collected.Usage, expectedUsage) {
+		t.Errorf("Expected usage %+v, got %+v", expectedUsage, collected.Usage)
+	}
+	if len(collected.ToolInvocations) != 2 || collected.ToolInvocations[0].Name != "Grep" || collected.ToolInvocations[1].FilePath != "search.go" {
+		t.Errorf("Expected Grep and Write invocations, got %+v", collected.ToolInvocations)
+	}
+	if !reflect.DeepEqual(collected.FilesChanged, []string{"search.go"}) || collected.SessionID != "session-2" {
+		t.Errorf("Expected changed files and session ID, got %+v", collected)
+	}
+}
@@ -0,0 +1,348 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Forges that host repositories
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+	ProviderGitea  = "gitea"
+)
+
+// States of a pull request
+const (
+	StateOpen   = "open"
+	StateClosed = "closed" // Closed without being merged
+	StateMerged = "merged"
+)
+
+// Review states of an open pull request
+const (
+	ReviewRequested        = "review-requested"  // Reviewers were asked and have not all answered
+	ReviewChangesRequested = "changes-requested" // A reviewer asked for changes
+	ReviewApproved         = "approved"
+)
+
+// States of the CI checks of an open pull request
+const (
+	ChecksPending = "pending"
+	ChecksSuccess = "success"
+	ChecksFailure = "failure"
+)
+
+// Repository identifies a repository on a forge
+type Repository struct {
+	Host  string // e.g. "github.com"
+	Owner string // User or organization; for GitLab the full group path
+	Name  string
+}
+
+// FullName returns the owner and name of the repository, e.g. "acme/shop"
+func (r Repository) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// NewPullRequest describes a pull request to open
+type NewPullRequest struct {
+	Title string
+	Body  string
+	Head  string // Branch with the changes
+	Base  string // Branch the changes should be merged into
+}
+
+// PullRequest is a pull request (a merge request on GitLab)
+type PullRequest struct {
+	Number int    `json:"number"` // The number shown on the forge, e.g. #12 or !12 on GitLab
+	URL    string `json:"url"`
+	State  string `json:"state"`            // One of the State constants
+	Review string `json:"review,omitempty"` // One of the Review constants; empty before anyone was asked to review
+	Checks string `json:"checks,omitempty"` // One of the Checks constants; empty when the repository runs no CI
+}
+
+// ReviewComment is a comment in a review thread
+type ReviewComment struct {
+	Author string `json:"author"`
+	Body   string `json:"body"`
+}
+
+// ReviewThread is an unresolved review conversation on a pull request
+type ReviewThread struct {
+	Path     string          `json:"path,omitempty"` // File the thread is on; empty for feedback on the whole pull request
+	Line     int             `json:"line,omitempty"`
+	DiffHunk string          `json:"diffHunk,omitempty"` // The lines of the diff the thread is about, where the forge reports them
+	Comments []ReviewComment `json:"comments"`
+}
+
+// Issue is an open issue in the forge's issue tracker
+type Issue struct {
+	Number int      `json:"number"`
+	URL    string   `json:"url"`
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels,omitempty"`
+	// BlockedBy lists the issues this one is linked as blocked by, on forges that track links
+	BlockedBy []int `json:"blockedBy,omitempty"`
+}
+
+// NewIssue describes an issue to open
+type NewIssue struct {
+	Title  string
+	Body   string
+	Labels []string
+}
+
+// Client talks to the API of the forge hosting a repository
+type Client interface {
+	// Name identifies the forge in messages, e.g. "GitHub"
+	Name() string
+	// CreatePullRequest opens a pull request
+	CreatePullRequest(ctx context.Context, req NewPullRequest) (PullRequest, error)
+	// GetPullRequest looks up the state of a pull request; the review and checks state are
+	// only filled in while it is open
+	GetPullRequest(ctx context.Context, number int) (PullRequest, error)
+	// ReviewThreads lists the unresolved review feedback on a pull request: open comment threads
+	// and the summaries of reviews that requested changes
+	ReviewThreads(ctx context.Context, number int) ([]ReviewThread, error)
+	// ListIssues lists the open issues that have all of the given labels
+	ListIssues(ctx context.Context, labels []string) ([]Issue, error)
+	// CreateIssue opens an issue
+	CreateIssue(ctx context.Context, req NewIssue) (Issue, error)
+}
+
+// Config selects and configures the forge of a repository. Access tokens are never stored in
+// it; they are read from the environment variable named by TokenEnv.
+type Config struct {
+	Provider string `json:"provider,omitempty"` // One of the Provider constants; empty detects it from the remote host
+	BaseURL  string `json:"baseUrl,omitempty"`  // API endpoint; defaults to the API of the remote host
+	TokenEnv string `json:"tokenEnv,omitempty"` // Environment variable holding the access token; defaults per provider
+}
+
+// defaultTokenEnvs are the environment variables each forge reads its token from by default,
+// in order of preference
+var defaultTokenEnvs = map[string][]string{
+	ProviderGitHub: {"GITHUB_TOKEN", "GITHUB_API_KEY"},
+	ProviderGitLab: {"GITLAB_TOKEN"},
+	ProviderGitea:  {"GITEA_TOKEN"},
+}
+
+// maxPages bounds how many pages of a list are fetched
+const maxPages = 20
+
+// Validate checks that the config names a known forge, if any
+func (c Config) Validate() error {
+	switch c.Provider {
+	case "", ProviderGitHub, ProviderGitLab, ProviderGitea:
+		return nil
+	default:
+		return fmt.Errorf("unknown forge: %s", c.Provider)
+	}
+}
+
+// DetectProvider guesses the forge from a repository host. It returns an empty string for
+// self-hosted forges it cannot recognize.
+func DetectProvider(host string) string {
+	host = strings.ToLower(host)
+	switch {
+	case strings.Contains(host, "github"):
+		return ProviderGitHub
+	case strings.Contains(host, "gitlab"):
+		return ProviderGitLab
+	case host == "codeberg.org" || strings.Contains(host, "gitea"):
+		return ProviderGitea
+	default:
+		return ""
+	}
+}
+
+// ParseRemoteURL extracts the repository from a git remote URL such as
+// https://github.com/acme/shop.git or git@gitlab.com:acme/backend/api.git
+func ParseRemoteURL(remote string) (Repository, error) {
+	remote = strings.TrimSpace(remote)
+	var host, path string
+	if strings.Contains(remote, "://") {
+		parsed, err := url.Parse(remote)
+		if err != nil {
+			return Repository{}, fmt.Errorf("invalid remote URL '%s': %v", remote, err)
+		}
+		host, path = parsed.Hostname(), parsed.Path
+	} else if at := strings.Index(remote, "@"); at >= 0 && strings.Contains(remote[at:], ":") {
+		// scp-like syntax: git@host:owner/name.git
+		rest := remote[at+1:]
+		colon := strings.Index(rest, ":")
+		host, path = rest[:colon], rest[colon+1:]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	slash := strings.LastIndex(path, "/")
+	if host == "" || slash <= 0 || slash == len(path)-1 {
+		return Repository{}, fmt.Errorf("cannot tell the repository from remote URL '%s'", remote)
+	}
+	return Repository{Host: host, Owner: path[:slash], Name: path[slash+1:]}, nil
+}
+
+// NewClient creates the Client for a repository. The forge is detected from the repository's
+// host unless the config names one.
+func NewClient(config Config, repo Repository) (Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	provider := config.Provider
+	if provider == "" {
+		provider = DetectProvider(repo.Host)
+	}
+	if provider == "" {
+		return nil, fmt.Errorf("cannot tell which forge hosts %s; set the forge provider in the settings", repo.Host)
+	}
+
+	envNames := defaultTokenEnvs[provider]
+	if config.TokenEnv != "" {
+		envNames = []string{config.TokenEnv}
+	}
+	token := ""
+	for _, envName := range envNames {
+		if token = os.Getenv(envName); token != "" {
+			break
+		}
+	}
+	if token == "" {
+		return nil, fmt.Errorf("%s environment variable is not set", strings.Join(envNames, " or "))
+	}
+
+	api := apiClient{baseURL: strings.TrimRight(config.BaseURL, "/"), client: http.DefaultClient}
+	switch provider {
+	case ProviderGitLab:
+		if api.baseURL == "" {
+			api.baseURL = "https://" + repo.Host + "/api/v4"
+		}
+		api.header = http.Header{"Private-Token": {token}}
+		return &gitlabClient{api: api, repo: repo}, nil
+	case ProviderGitea:
+		if api.baseURL == "" {
+			api.baseURL = "https://" + repo.Host + "/api/v1"
+		}
+		api.header = http.Header{"Authorization": {"token " + token}}
+		return &giteaClient{api: api, repo: repo}, nil
+	default:
+		if api.baseURL == "" {
+			api.baseURL = "https://api.github.com"
+			if repo.Host != "github.com" {
+				api.baseURL = "https://" + repo.Host + "/api/v3" // GitHub Enterprise Server
+			}
+		}
+		api.header = http.Header{"Authorization": {"Bearer " + token}, "Accept": {"application/vnd.github+json"}}
+		// The GraphQL API lives next to the REST API, e.g. /api/graphql next to /api/v3
+		graphql := api
+		graphql.baseURL = strings.TrimSuffix(api.baseURL, "/v3") + "/graphql"
+		return &githubClient{api: api, graphql: graphql, repo: repo}, nil
+	}
+}
+
+// combineChecks reduces the states of several CI checks to one: any failure fails them all and
+// any pending check keeps them pending
+func combineChecks(states []string) string {
+	combined := ""
+	for _, state := range states {
+		switch state {
+		case ChecksFailure:
+			return ChecksFailure
+		case ChecksPending:
+			combined = ChecksPending
+		case ChecksSuccess:
+			if combined == "" {
+				combined = ChecksSuccess
+			}
+		}
+	}
+	return combined
+}
+
+// apiClient sends JSON requests to a forge API
+type apiClient struct {
+	baseURL string
+	header  http.Header // Authentication and other headers sent with every request
+	client  *http.Client
+}
+
+// do sends a request with an optional JSON body to path and decodes the JSON response into out
+func (c apiClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned status %d: %s", method, path, resp.StatusCode, errorMessage(data))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("unexpected response from %s %s: %v", method, path, err)
+	}
+	return nil
+}
+
+// errorMessage extracts the message of a forge API error response
+func errorMessage(data []byte) string {
+	var parsed struct {
+		Message interface{} `json:"message"`
+		Error   string      `json:"error"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &parsed); err == nil {
+		var parts []string
+		if parsed.Message != nil {
+			parts = append(parts, fmt.Sprint(parsed.Message))
+		}
+		if parsed.Error != "" {
+			parts = append(parts, parsed.Error)
+		}
+		for _, e := range parsed.Errors {
+			if e.Message != "" {
+				parts = append(parts, e.Message)
+			}
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, ": ")
+		}
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,349 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	remotes := map[string]Repository{
+		"https://github.com/acme/shop.git":             {Host: "github.com", Owner: "acme", Name: "shop"},
+		"https://token@github.com/acme/shop":           {Host: "github.com", Owner: "acme", Name: "shop"},
+		"git@gitlab.com:acme/backend/api.git":          {Host: "gitlab.com", Owner: "acme/backend", Name: "api"},
+		"ssh://git@git.example.com:2222/team/tool.git": {Host: "git.example.com", Owner: "team", Name: "tool"},
+	}
+	for remote, expected := range remotes {
+		repo, err := ParseRemoteURL(remote)
+		if err != nil || repo != expected {
+			t.Errorf("%s: expected %+v, got %+v (%v)", remote, expected, repo, err)
+		}
+	}
+
+	for _, remote := range []string{"", "/local/path/repo", "https://github.com/shop"} {
+		if repo, err := ParseRemoteURL(remote); err == nil {
+			t.Errorf("Expected %q to be rejected, got %+v", remote, repo)
+		}
+	}
+}
+
+func TestNewClientDetectsProvider(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "gh")
+	t.Setenv("GITLAB_TOKEN", "")
+
+	client, err := NewClient(Config{}, Repository{Host: "github.com", Owner: "acme", Name: "shop"})
+	if err != nil || client.Name() != "GitHub" {
+		t.Errorf("Expected a GitHub client, got %v (%v)", client, err)
+	}
+	if _, err := NewClient(Config{}, Repository{Host: "gitlab.com", Owner: "acme", Name: "shop"}); err == nil {
+		t.Error("Expected a missing GitLab token to be rejected")
+	}
+	if _, err := NewClient(Config{}, Repository{Host: "git.example.com", Owner: "acme", Name: "shop"}); err == nil {
+		t.Error("Expected an unknown host without provider to be rejected")
+	}
+	if err := (Config{Provider: "bitbucket"}).Validate(); err == nil {
+		t.Error("Expected an unknown provider to be rejected")
+	}
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	tests := []struct {
+		provider string
+		path     string
+		header   string
+		value    string
+		response string
+		fields   map[string]string
+		expected PullRequest
+	}{
+		{
+			provider: ProviderGitHub,
+			path:     "/repos/acme/shop/pulls",
+			header:   "Authorization",
+			value:    "Bearer secret",
+			response: `{"number":7,"html_url":"https://github.com/acme/shop/pull/7","state":"open"}`,
+			fields:   map[string]string{"title": "Add login", "head": "task-1-add-login", "base": "main", "body": "Body"},
+			expected: PullRequest{Number: 7, URL: "https://github.com/acme/shop/pull/7", State: StateOpen},
+		},
+		{
+			provider: ProviderGitLab,
+			path:     "/projects/acme%2Fshop/merge_requests",
+			header:   "Private-Token",
+			value:    "secret",
+			response: `{"iid":3,"web_url":"https://gitlab.com/acme/shop/-/merge_requests/3","state":"opened"}`,
+			fields:   map[string]string{"title": "Add login", "source_branch": "task-1-add-login", "target_branch": "main", "description": "Body"},
+			expected: PullRequest{Number: 3, URL: "https://gitlab.com/acme/shop/-/merge_requests/3", State: StateOpen},
+		},
+		{
+			provider: ProviderGitea,
+			path:     "/repos/acme/shop/pulls",
+			header:   "Authorization",
+			value:    "token secret",
+			response: `{"number":5,"html_url":"https://gitea.example.com/acme/shop/pulls/5","state":"open"}`,
+			fields:   map[string]string{"title": "Add login", "head": "task-1-add-login", "base": "main", "body": "Body"},
+			expected: PullRequest{Number: 5, URL: "https://gitea.example.com/acme/shop/pulls/5", State: StateOpen},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.provider, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.EscapedPath() != test.path || r.Header.Get(test.header) != test.value {
+					t.Errorf("Unexpected request %s %s with %s %q", r.Method, r.URL.EscapedPath(), test.header, r.Header.Get(test.header))
+				}
+				var received map[string]string
+				json.NewDecoder(r.Body).Decode(&received)
+				for field, value := range test.fields {
+					if received[field] != value {
+						t.Errorf("Expected %s %q, got %q", field, value, received[field])
+					}
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			t.Setenv("FORGE_TEST_TOKEN", "secret")
+			client, err := NewClient(Config{Provider: test.provider, BaseURL: server.URL, TokenEnv: "FORGE_TEST_TOKEN"}, Repository{Host: "example.com", Owner: "acme", Name: "shop"})
+			if err != nil {
+				t.Fatalf("Expected client, got: %v", err)
+			}
+			pr, err := client.CreatePullRequest(context.Background(), NewPullRequest{Title: "Add login", Body: "Body", Head: "task-1-add-login", Base: "main"})
+			if err != nil || pr != test.expected {
+				t.Errorf("Expected %+v, got %+v (%v)", test.expected, pr, err)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequestReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message":"Validation Failed","errors":[{"message":"A pull request already exists for acme:task-1."}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("GITHUB_TOKEN", "secret")
+	client, _ := NewClient(Config{Provider: ProviderGitHub, BaseURL: server.URL}, Repository{Host: "github.com", Owner: "acme", Name: "shop"})
+	_, err := client.CreatePullRequest(context.Background(), NewPullRequest{Title: "Add login", Head: "task-1", Base: "main"})
+	if err == nil || err.Error() != "POST /repos/acme/shop/pulls returned status 422: Validation Failed: A pull request already exists for acme:task-1." {
+		t.Errorf("Expected the API error message, got: %v", err)
+	}
+}
+
+func TestGetPullRequest(t *testing.T) {
+	tests := []struct {
+		provider  string
+		responses map[string]string
+		expected  PullRequest
+	}{
+		{
+			provider: ProviderGitHub,
+			responses: map[string]string{
+				"/repos/acme/shop/pulls/7":                `{"number":7,"html_url":"https://github.com/acme/shop/pull/7","state":"open","head":{"sha":"abc"},"requested_reviewers":[{"login":"carol"}]}`,
+				"/repos/acme/shop/pulls/7/reviews":        `[{"user":{"login":"bob"},"state":"CHANGES_REQUESTED"},{"user":{"login":"bob"},"state":"APPROVED"},{"user":{"login":"dan"},"state":"COMMENTED"}]`,
+				"/repos/acme/shop/commits/abc/status":     `{"state":"pending","total_count":0}`,
+				"/repos/acme/shop/commits/abc/check-runs": `{"check_runs":[{"status":"completed","conclusion":"success"},{"status":"completed","conclusion":"failure"}]}`,
+			},
+			expected: PullRequest{Number: 7, URL: "https://github.com/acme/shop/pull/7", State: StateOpen, Review: ReviewRequested, Checks: ChecksFailure},
+		},
+		{
+			provider: ProviderGitHub,
+			responses: map[string]string{
+				"/repos/acme/shop/pulls/7": `{"number":7,"html_url":"https://github.com/acme/shop/pull/7","state":"closed","merged":true}`,
+			},
+			expected: PullRequest{Number: 7, URL: "https://github.com/acme/shop/pull/7", State: StateMerged},
+		},
+		{
+			provider: ProviderGitLab,
+			responses: map[string]string{
+				"/projects/acme%2Fshop/merge_requests/7":           `{"iid":7,"web_url":"https://gitlab.com/acme/shop/-/merge_requests/7","state":"opened","reviewers":[{"username":"bob"}],"head_pipeline":{"status":"running"}}`,
+				"/projects/acme%2Fshop/merge_requests/7/approvals": `{"approved_by":[{"user":{"username":"bob"}}]}`,
+			},
+			expected: PullRequest{Number: 7, URL: "https://gitlab.com/acme/shop/-/merge_requests/7", State: StateOpen, Review: ReviewApproved, Checks: ChecksPending},
+		},
+		{
+			provider: ProviderGitea,
+			responses: map[string]string{
+				"/repos/acme/shop/pulls/7":            `{"number":7,"html_url":"https://gitea.example.com/acme/shop/pulls/7","state":"open","head":{"sha":"abc"}}`,
+				"/repos/acme/shop/pulls/7/reviews":    `[{"user":{"login":"bob"},"state":"REQUEST_CHANGES"}]`,
+				"/repos/acme/shop/commits/abc/status": `{"state":"success","total_count":2}`,
+			},
+			expected: PullRequest{Number: 7, URL: "https://gitea.example.com/acme/shop/pulls/7", State: StateOpen, Review: ReviewChangesRequested, Checks: ChecksSuccess},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.provider, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response, ok := test.responses[r.URL.EscapedPath()]
+				if r.Method != http.MethodGet || !ok {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.EscapedPath())
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(response))
+			}))
+			defer server.Close()
+
+			t.Setenv("FORGE_TEST_TOKEN", "secret")
+			client, err := NewClient(Config{Provider: test.provider, BaseURL: server.URL, TokenEnv: "FORGE_TEST_TOKEN"}, Repository{Host: "example.com", Owner: "acme", Name: "shop"})
+			if err != nil {
+				t.Fatalf("Expected client, got: %v", err)
+			}
+			pr, err := client.GetPullRequest(context.Background(), 7)
+			if err != nil || pr != test.expected {
+				t.Errorf("Expected %+v, got %+v (%v)", test.expected, pr, err)
+			}
+		})
+	}
+}
+
+func TestReviewThreads(t *testing.T) {
+	tests := []struct {
+		provider  string
+		responses map[string]string
+		expected  []ReviewThread
+	}{
+		{
+			provider: ProviderGitHub,
+			responses: map[string]string{
+				"/graphql": `{"data":{"repository":{"pullRequest":{
+					"reviewThreads":{"nodes":[
+						{"isResolved":true,"path":"done.go","line":1,"comments":{"nodes":[{"author":{"login":"bob"},"body":"Fixed"}]}},
+						{"isResolved":false,"path":"login.go","line":null,"originalLine":12,"comments":{"nodes":[
+							{"author":{"login":"bob"},"body":"Check the error","diffHunk":"@@ -10,3 +10,4 @@"},
+							{"author":null,"body":"Agreed"}]}}]},
+					"reviews":{"nodes":[
+						{"databaseId":1,"author":{"login":"carol"},"state":"CHANGES_REQUESTED","body":"Old request"},
+						{"databaseId":2,"author":{"login":"carol"},"state":"APPROVED","body":"Thanks"},
+						{"databaseId":3,"author":{"login":"bob"},"state":"CHANGES_REQUESTED","body":"Needs tests"}]}}}}}`,
+			},
+			expected: []ReviewThread{
+				{Comments: []ReviewComment{{Author: "bob", Body: "Needs tests"}}},
+				{Path: "login.go", Line: 12, DiffHunk: "@@ -10,3 +10,4 @@", Comments: []ReviewComment{{Author: "bob", Body: "Check the error"}, {Author: "ghost", Body: "Agreed"}}},
+			},
+		},
+		{
+			provider: ProviderGitLab,
+			responses: map[string]string{
+				"/projects/acme%2Fshop/merge_requests/7/discussions": `[
+					{"notes":[{"author":{"username":"bob"},"body":"Rename this","resolvable":true,"resolved":false,"position":{"new_path":"login.go","new_line":4}},
+						{"author":{"username":"eve"},"body":"+1","resolvable":true,"resolved":false}]},
+					{"notes":[{"author":{"username":"bob"},"body":"Done","resolvable":true,"resolved":true}]},
+					{"notes":[{"author":{"username":"gitlab"},"body":"added 1 commit","system":true}]}]`,
+			},
+			expected: []ReviewThread{
+				{Path: "login.go", Line: 4, Comments: []ReviewComment{{Author: "bob", Body: "Rename this"}, {Author: "eve", Body: "+1"}}},
+			},
+		},
+		{
+			provider: ProviderGitea,
+			responses: map[string]string{
+				"/repos/acme/shop/pulls/7/reviews":            `[{"id":9,"user":{"login":"bob"},"state":"REQUEST_CHANGES","body":"","comments_count":2}]`,
+				"/repos/acme/shop/pulls/7/reviews/9/comments": `[{"user":{"login":"bob"},"body":"Typo","path":"README.md","position":3,"diff_hunk":"@@ -1 +1 @@"},{"user":{"login":"bob"},"body":"Done","path":"a.go","resolver":{"login":"amy"}}]`,
+			},
+			expected: []ReviewThread{
+				{Path: "README.md", Line: 3, DiffHunk: "@@ -1 +1 @@", Comments: []ReviewComment{{Author: "bob", Body: "Typo"}}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.provider, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response, ok := test.responses[r.URL.EscapedPath()]
+				if !ok {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.EscapedPath())
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(response))
+			}))
+			defer server.Close()
+
+			t.Setenv("FORGE_TEST_TOKEN", "secret")
+			client, err := NewClient(Config{Provider: test.provider, BaseURL: server.URL, TokenEnv: "FORGE_TEST_TOKEN"}, Repository{Host: "example.com", Owner: "acme", Name: "shop"})
+			if err != nil {
+				t.Fatalf("Expected client, got: %v", err)
+			}
+			threads, err := client.ReviewThreads(context.Background(), 7)
+			if err != nil || !reflect.DeepEqual(threads, test.expected) {
+				t.Errorf("Expected %+v, got %+v (%v)", test.expected, threads, err)
+			}
+		})
+	}
+}
+
+func TestListIssues(t *testing.T) {
+	tests := []struct {
+		provider  string
+		responses map[string]string
+		query     string
+		expected  []Issue
+	}{
+		{
+			provider: ProviderGitHub,
+			responses: map[string]string{
+				"/repos/acme/shop/issues": `[{"number":4,"html_url":"https://github.com/acme/shop/issues/4","title":"Add login","body":"Depends on #3","labels":[{"name":"backlog"},{"name":"P1"}]},
+					{"number":5,"title":"A pull request","pull_request":{}}]`,
+			},
+			query:    "labels=backlog&page=1&per_page=100&state=open",
+			expected: []Issue{{Number: 4, URL: "https://github.com/acme/shop/issues/4", Title: "Add login", Body: "Depends on #3", Labels: []string{"backlog", "P1"}}},
+		},
+		{
+			provider: ProviderGitLab,
+			responses: map[string]string{
+				"/projects/acme%2Fshop/issues":         `[{"iid":4,"project_id":1,"web_url":"https://gitlab.com/acme/shop/-/issues/4","title":"Add login","description":"Login","labels":["backlog"]}]`,
+				"/projects/acme%2Fshop/issues/4/links": `[{"iid":3,"project_id":1,"link_type":"is_blocked_by"},{"iid":2,"project_id":1,"link_type":"relates_to"},{"iid":9,"project_id":8,"link_type":"is_blocked_by"}]`,
+			},
+			query:    "labels=backlog&page=1&per_page=100&state=opened",
+			expected: []Issue{{Number: 4, URL: "https://gitlab.com/acme/shop/-/issues/4", Title: "Add login", Body: "Login", Labels: []string{"backlog"}, BlockedBy: []int{3}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.provider, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response, ok := test.responses[r.URL.EscapedPath()]
+				if !ok {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.EscapedPath())
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if strings.HasSuffix(r.URL.Path, "/issues") && r.URL.RawQuery != test.query {
+					t.Errorf("Expected query %q, got %q", test.query, r.URL.RawQuery)
+				}
+				w.Write([]byte(response))
+			}))
+			defer server.Close()
+
+			t.Setenv("FORGE_TEST_TOKEN", "secret")
+			client, err := NewClient(Config{Provider: test.provider, BaseURL: server.URL, TokenEnv: "FORGE_TEST_TOKEN"}, Repository{Host: "example.com", Owner: "acme", Name: "shop"})
+			if err != nil {
+				t.Fatalf("Expected client, got: %v", err)
+			}
+			issues, err := client.ListIssues(context.Background(), []string{"backlog"})
+			if err != nil || !reflect.DeepEqual(issues, test.expected) {
+				t.Errorf("Expected %+v, got %+v (%v)", test.expected, issues, err)
+			}
+		})
+	}
+}
+
+func TestGitHubTokenFallsBackToAPIKey(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITHUB_API_KEY", "")
+	if _, err := NewClient(Config{}, Repository{Host: "github.com", Owner: "acme", Name: "shop"}); err == nil || err.Error() != "GITHUB_TOKEN or GITHUB_API_KEY environment variable is not set" {
+		t.Errorf("Expected both variables to be named, got: %v", err)
+	}
+
+	t.Setenv("GITHUB_API_KEY", "ghp_key")
+	if _, err := NewClient(Config{}, Repository{Host: "github.com", Owner: "acme", Name: "shop"}); err != nil {
+		t.Errorf("Expected GITHUB_API_KEY to be used, got: %v", err)
+	}
+}
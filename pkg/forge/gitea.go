@@ -0,0 +1,172 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// giteaClient talks to the Gitea (and Forgejo) API, which models pull requests like GitHub
+type giteaClient struct {
+	api  apiClient
+	repo Repository
+}
+
+// Name identifies the forge in messages
+func (c *giteaClient) Name() string {
+	return "Gitea"
+}
+
+// CreatePullRequest opens a pull request
+func (c *giteaClient) CreatePullRequest(ctx context.Context, req NewPullRequest) (PullRequest, error) {
+	body := map[string]string{
+		"title": req.Title,
+		"body":  req.Body,
+		"head":  req.Head,
+		"base":  req.Base,
+	}
+
+	var created githubPullRequest
+	path := fmt.Sprintf("/repos/%s/%s/pulls", c.repo.Owner, c.repo.Name)
+	if err := c.api.do(ctx, http.MethodPost, path, body, &created); err != nil {
+		return PullRequest{}, err
+	}
+	return created.pullRequest(), nil
+}
+
+// GetPullRequest looks up a pull request with its reviews and the status of its head commit
+func (c *giteaClient) GetPullRequest(ctx context.Context, number int) (PullRequest, error) {
+	var fetched githubPullRequest
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", c.repo.Owner, c.repo.Name, number)
+	if err := c.api.do(ctx, http.MethodGet, path, nil, &fetched); err != nil {
+		return PullRequest{}, err
+	}
+	pr := fetched.pullRequest()
+	if pr.State != StateOpen {
+		return pr, nil
+	}
+
+	var reviews []githubReview
+	if err := c.api.do(ctx, http.MethodGet, path+"/reviews", nil, &reviews); err != nil {
+		return PullRequest{}, err
+	}
+	pr.Review = reviewState(reviews, len(fetched.RequestedReviewers)+len(fetched.RequestedTeams) > 0)
+
+	var status githubCommitStatus
+	statusPath := fmt.Sprintf("/repos/%s/%s/commits/%s/status", c.repo.Owner, c.repo.Name, fetched.Head.SHA)
+	if err := c.api.do(ctx, http.MethodGet, statusPath, nil, &status); err != nil {
+		return PullRequest{}, err
+	}
+	pr.Checks = status.checks()
+	return pr, nil
+}
+
+// ReviewThreads lists the unresolved review comments and the reviews requesting changes. Gitea
+// has no threads in its API, so every comment is a thread of its own.
+func (c *giteaClient) ReviewThreads(ctx context.Context, number int) ([]ReviewThread, error) {
+	var reviews []githubReview
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", c.repo.Owner, c.repo.Name, number)
+	if err := c.api.do(ctx, http.MethodGet, path, nil, &reviews); err != nil {
+		return nil, err
+	}
+
+	threads := changesRequestedThreads(reviews)
+	for _, review := range reviews {
+		if review.CommentsCount == 0 {
+			continue
+		}
+		var comments []struct {
+			User struct {
+				Login string `json:"login"`
+			} `json:"user"`
+			Body             string    `json:"body"`
+			Path             string    `json:"path"`
+			DiffHunk         string    `json:"diff_hunk"`
+			Position         int       `json:"position"`
+			OriginalPosition int       `json:"original_position"`
+			Resolver         *struct{} `json:"resolver"`
+		}
+		if err := c.api.do(ctx, http.MethodGet, fmt.Sprintf("%s/%d/comments", path, review.ID), nil, &comments); err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			if comment.Resolver != nil {
+				continue
+			}
+			line := comment.Position
+			if line == 0 {
+				line = comment.OriginalPosition
+			}
+			threads = append(threads, ReviewThread{
+				Path:     comment.Path,
+				Line:     line,
+				DiffHunk: comment.DiffHunk,
+				Comments: []ReviewComment{{Author: comment.User.Login, Body: comment.Body}},
+			})
+		}
+	}
+	return threads, nil
+}
+
+// ListIssues lists the open issues that have all of the given labels
+func (c *giteaClient) ListIssues(ctx context.Context, labels []string) ([]Issue, error) {
+	const perPage = 50 // The default maximum page size of Gitea
+	query := url.Values{"state": {"open"}, "type": {"issues"}, "limit": {fmt.Sprint(perPage)}}
+	if len(labels) > 0 {
+		query.Set("labels", strings.Join(labels, ","))
+	}
+
+	var issues []Issue
+	for page := 1; page <= maxPages; page++ {
+		query.Set("page", fmt.Sprint(page))
+		var listed []githubIssue
+		path := fmt.Sprintf("/repos/%s/%s/issues?%s", c.repo.Owner, c.repo.Name, query.Encode())
+		if err := c.api.do(ctx, http.MethodGet, path, nil, &listed); err != nil {
+			return nil, err
+		}
+		for _, issue := range listed {
+			issues = append(issues, issue.issue())
+		}
+		if len(listed) < perPage {
+			break
+		}
+	}
+	return issues, nil
+}
+
+// CreateIssue opens an issue. Gitea takes label IDs, so labels are looked up by name and
+// labels the repository does not have are left out.
+func (c *giteaClient) CreateIssue(ctx context.Context, req NewIssue) (Issue, error) {
+	labelIDs := []int64{}
+	if len(req.Labels) > 0 {
+		var repoLabels []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		}
+		path := fmt.Sprintf("/repos/%s/%s/labels?limit=50", c.repo.Owner, c.repo.Name)
+		if err := c.api.do(ctx, http.MethodGet, path, nil, &repoLabels); err != nil {
+			return Issue{}, err
+		}
+		for _, label := range repoLabels {
+			for _, name := range req.Labels {
+				if strings.EqualFold(label.Name, name) {
+					labelIDs = append(labelIDs, label.ID)
+				}
+			}
+		}
+	}
+
+	body := map[string]interface{}{
+		"title":  req.Title,
+		"body":   req.Body,
+		"labels": labelIDs,
+	}
+	var created githubIssue
+	path := fmt.Sprintf("/repos/%s/%s/issues", c.repo.Owner, c.repo.Name)
+	if err := c.api.do(ctx, http.MethodPost, path, body, &created); err != nil {
+		return Issue{}, err
+	}
+	return created.issue(), nil
+}
@@ -0,0 +1,365 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// githubClient talks to the GitHub REST API
+type githubClient struct {
+	api     apiClient
+	graphql apiClient // Only the GraphQL API tells whether review threads are resolved
+	repo    Repository
+}
+
+// githubPullRequest is the part of a GitHub pull request that is used
+type githubPullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Merged  bool   `json:"merged"`
+	Head    struct {
+		SHA string `json:"sha"`
+	} `json:"head"`
+	RequestedReviewers []struct{} `json:"requested_reviewers"`
+	RequestedTeams     []struct{} `json:"requested_teams"`
+}
+
+// githubReview is a review of a pull request on GitHub or Gitea
+type githubReview struct {
+	ID   int64 `json:"id"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	State         string `json:"state"`
+	Body          string `json:"body"`
+	CommentsCount int    `json:"comments_count"` // Only reported by Gitea
+}
+
+// githubCommitStatus is the combined commit status of a commit on GitHub or Gitea
+type githubCommitStatus struct {
+	State      string `json:"state"` // "pending", "success", "failure" or "error"
+	TotalCount int    `json:"total_count"`
+}
+
+// checks converts the combined commit status; a commit without statuses has no checks
+func (s githubCommitStatus) checks() string {
+	if s.TotalCount == 0 {
+		return ""
+	}
+	switch s.State {
+	case "success":
+		return ChecksSuccess
+	case "pending":
+		return ChecksPending
+	default:
+		return ChecksFailure
+	}
+}
+
+// latestReviews returns the latest review of each reviewer that approved, requested changes or
+// had a review dismissed, keyed by reviewer
+func latestReviews(reviews []githubReview) map[string]githubReview {
+	latest := map[string]githubReview{}
+	for _, review := range reviews {
+		switch review.State {
+		case "APPROVED", "CHANGES_REQUESTED", "REQUEST_CHANGES", "DISMISSED":
+			latest[review.User.Login] = review
+		}
+	}
+	return latest
+}
+
+// changesRequestedThreads turns the summaries of reviews that still request changes into
+// review threads on the whole pull request
+func changesRequestedThreads(reviews []githubReview) []ReviewThread {
+	var threads []ReviewThread
+	latest := latestReviews(reviews)
+	for _, review := range reviews {
+		current, ok := latest[review.User.Login]
+		if !ok || current.ID != review.ID || strings.TrimSpace(review.Body) == "" {
+			continue
+		}
+		if review.State == "CHANGES_REQUESTED" || review.State == "REQUEST_CHANGES" {
+			threads = append(threads, ReviewThread{Comments: []ReviewComment{{Author: review.User.Login, Body: review.Body}}})
+		}
+	}
+	return threads
+}
+
+// reviewState derives the review state of a pull request from the latest review of each
+// reviewer. Requested changes win over pending review requests, which win over approvals.
+func reviewState(reviews []githubReview, reviewRequested bool) string {
+	approved := false
+	for _, review := range latestReviews(reviews) {
+		switch review.State {
+		case "CHANGES_REQUESTED", "REQUEST_CHANGES":
+			return ReviewChangesRequested
+		case "APPROVED":
+			approved = true
+		}
+	}
+	if reviewRequested {
+		return ReviewRequested
+	}
+	if approved {
+		return ReviewApproved
+	}
+	return ""
+}
+
+// pullRequest converts a GitHub pull request
+func (p githubPullRequest) pullRequest() PullRequest {
+	state := p.State
+	if p.Merged {
+		state = StateMerged
+	}
+	return PullRequest{Number: p.Number, URL: p.HTMLURL, State: state}
+}
+
+// Name identifies the forge in messages
+func (c *githubClient) Name() string {
+	return "GitHub"
+}
+
+// CreatePullRequest opens a pull request
+func (c *githubClient) CreatePullRequest(ctx context.Context, req NewPullRequest) (PullRequest, error) {
+	body := map[string]string{
+		"title": req.Title,
+		"body":  req.Body,
+		"head":  req.Head,
+		"base":  req.Base,
+	}
+
+	var created githubPullRequest
+	path := fmt.Sprintf("/repos/%s/%s/pulls", c.repo.Owner, c.repo.Name)
+	if err := c.api.do(ctx, http.MethodPost, path, body, &created); err != nil {
+		return PullRequest{}, err
+	}
+	return created.pullRequest(), nil
+}
+
+// GetPullRequest looks up a pull request with its reviews and the checks of its head commit
+func (c *githubClient) GetPullRequest(ctx context.Context, number int) (PullRequest, error) {
+	var fetched githubPullRequest
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", c.repo.Owner, c.repo.Name, number)
+	if err := c.api.do(ctx, http.MethodGet, path, nil, &fetched); err != nil {
+		return PullRequest{}, err
+	}
+	pr := fetched.pullRequest()
+	if pr.State != StateOpen {
+		return pr, nil
+	}
+
+	var reviews []githubReview
+	if err := c.api.do(ctx, http.MethodGet, path+"/reviews?per_page=100", nil, &reviews); err != nil {
+		return PullRequest{}, err
+	}
+	pr.Review = reviewState(reviews, len(fetched.RequestedReviewers)+len(fetched.RequestedTeams) > 0)
+
+	// Commit statuses and check runs are two separate CI APIs on GitHub
+	commitPath := fmt.Sprintf("/repos/%s/%s/commits/%s", c.repo.Owner, c.repo.Name, fetched.Head.SHA)
+	var status githubCommitStatus
+	if err := c.api.do(ctx, http.MethodGet, commitPath+"/status", nil, &status); err != nil {
+		return PullRequest{}, err
+	}
+	var checkRuns struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := c.api.do(ctx, http.MethodGet, commitPath+"/check-runs?per_page=100", nil, &checkRuns); err != nil {
+		return PullRequest{}, err
+	}
+
+	states := []string{status.checks()}
+	for _, run := range checkRuns.CheckRuns {
+		switch {
+		case run.Status != "completed":
+			states = append(states, ChecksPending)
+		case run.Conclusion == "success" || run.Conclusion == "neutral" || run.Conclusion == "skipped":
+			states = append(states, ChecksSuccess)
+		default:
+			states = append(states, ChecksFailure)
+		}
+	}
+	pr.Checks = combineChecks(states)
+	return pr, nil
+}
+
+// githubReviewThreadsQuery fetches the review threads and reviews of a pull request
+const githubReviewThreadsQuery = `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100) {
+        nodes {
+          isResolved
+          path
+          line
+          originalLine
+          comments(first: 50) { nodes { author { login } body diffHunk } }
+        }
+      }
+      reviews(last: 100) { nodes { databaseId author { login } state body } }
+    }
+  }
+}`
+
+// githubAuthor is the author of a GraphQL node; it is null for deleted accounts
+type githubAuthor *struct {
+	Login string `json:"login"`
+}
+
+// login returns the login of an author, or "ghost" like GitHub does for deleted accounts
+func login(author githubAuthor) string {
+	if author == nil {
+		return "ghost"
+	}
+	return author.Login
+}
+
+// ReviewThreads lists the unresolved review threads and the reviews requesting changes
+func (c *githubClient) ReviewThreads(ctx context.Context, number int) ([]ReviewThread, error) {
+	request := map[string]interface{}{
+		"query":     githubReviewThreadsQuery,
+		"variables": map[string]interface{}{"owner": c.repo.Owner, "name": c.repo.Name, "number": number},
+	}
+	var response struct {
+		Data struct {
+			Repository struct {
+				PullRequest *struct {
+					ReviewThreads struct {
+						Nodes []struct {
+							IsResolved   bool   `json:"isResolved"`
+							Path         string `json:"path"`
+							Line         int    `json:"line"`
+							OriginalLine int    `json:"originalLine"`
+							Comments     struct {
+								Nodes []struct {
+									Author   githubAuthor `json:"author"`
+									Body     string       `json:"body"`
+									DiffHunk string       `json:"diffHunk"`
+								} `json:"nodes"`
+							} `json:"comments"`
+						} `json:"nodes"`
+					} `json:"reviewThreads"`
+					Reviews struct {
+						Nodes []struct {
+							DatabaseID int64        `json:"databaseId"`
+							Author     githubAuthor `json:"author"`
+							State      string       `json:"state"`
+							Body       string       `json:"body"`
+						} `json:"nodes"`
+					} `json:"reviews"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := c.graphql.do(ctx, http.MethodPost, "", request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("GraphQL query failed: %s", response.Errors[0].Message)
+	}
+	pr := response.Data.Repository.PullRequest
+	if pr == nil {
+		return nil, fmt.Errorf("pull request #%d not found", number)
+	}
+
+	var threads []ReviewThread
+	for _, node := range pr.ReviewThreads.Nodes {
+		if node.IsResolved || len(node.Comments.Nodes) == 0 {
+			continue
+		}
+		thread := ReviewThread{Path: node.Path, Line: node.Line, DiffHunk: node.Comments.Nodes[0].DiffHunk}
+		if thread.Line == 0 {
+			thread.Line = node.OriginalLine // The thread is on lines that have changed since
+		}
+		for _, comment := range node.Comments.Nodes {
+			thread.Comments = append(thread.Comments, ReviewComment{Author: login(comment.Author), Body: comment.Body})
+		}
+		threads = append(threads, thread)
+	}
+
+	reviews := make([]githubReview, len(pr.Reviews.Nodes))
+	for i, node := range pr.Reviews.Nodes {
+		reviews[i] = githubReview{ID: node.DatabaseID, State: node.State, Body: node.Body}
+		reviews[i].User.Login = login(node.Author)
+	}
+	return append(changesRequestedThreads(reviews), threads...), nil
+}
+
+// githubIssue is the part of a GitHub or Gitea issue that is used
+type githubIssue struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	PullRequest *struct{} `json:"pull_request"` // Set when the issue is a pull request
+}
+
+// issue converts a GitHub or Gitea issue
+func (i githubIssue) issue() Issue {
+	issue := Issue{Number: i.Number, URL: i.HTMLURL, Title: i.Title, Body: i.Body}
+	for _, label := range i.Labels {
+		issue.Labels = append(issue.Labels, label.Name)
+	}
+	return issue
+}
+
+// ListIssues lists the open issues that have all of the given labels. GitHub lists pull
+// requests as issues too; they are left out.
+func (c *githubClient) ListIssues(ctx context.Context, labels []string) ([]Issue, error) {
+	const perPage = 100
+	query := url.Values{"state": {"open"}, "per_page": {fmt.Sprint(perPage)}}
+	if len(labels) > 0 {
+		query.Set("labels", strings.Join(labels, ","))
+	}
+
+	var issues []Issue
+	for page := 1; page <= maxPages; page++ {
+		query.Set("page", fmt.Sprint(page))
+		var listed []githubIssue
+		path := fmt.Sprintf("/repos/%s/%s/issues?%s", c.repo.Owner, c.repo.Name, query.Encode())
+		if err := c.api.do(ctx, http.MethodGet, path, nil, &listed); err != nil {
+			return nil, err
+		}
+		for _, issue := range listed {
+			if issue.PullRequest == nil {
+				issues = append(issues, issue.issue())
+			}
+		}
+		if len(listed) < perPage {
+			break
+		}
+	}
+	return issues, nil
+}
+
+// CreateIssue opens an issue. Labels that do not exist yet are created by GitHub.
+func (c *githubClient) CreateIssue(ctx context.Context, req NewIssue) (Issue, error) {
+	body := map[string]interface{}{
+		"title": req.Title,
+		"body":  req.Body,
+	}
+	if len(req.Labels) > 0 {
+		body["labels"] = req.Labels
+	}
+
+	var created githubIssue
+	path := fmt.Sprintf("/repos/%s/%s/issues", c.repo.Owner, c.repo.Name)
+	if err := c.api.do(ctx, http.MethodPost, path, body, &created); err != nil {
+		return Issue{}, err
+	}
+	return created.issue(), nil
+}
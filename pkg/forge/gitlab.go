@@ -0,0 +1,229 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// gitlabClient talks to the GitLab REST API, where pull requests are merge requests
+type gitlabClient struct {
+	api  apiClient
+	repo Repository
+}
+
+// gitlabMergeRequest is the part of a GitLab merge request that is used
+type gitlabMergeRequest struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+	State  string `json:"state"` // "opened", "closed", "locked" or "merged"
+	// DetailedMergeStatus is "requested_changes" once a reviewer asked for changes
+	DetailedMergeStatus string     `json:"detailed_merge_status"`
+	Reviewers           []struct{} `json:"reviewers"`
+	HeadPipeline        *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+}
+
+// pullRequest converts a GitLab merge request
+func (m gitlabMergeRequest) pullRequest() PullRequest {
+	state := m.State
+	switch m.State {
+	case "opened", "locked":
+		state = StateOpen
+	}
+	return PullRequest{Number: m.IID, URL: m.WebURL, State: state}
+}
+
+// projectPath returns the API path of the repository's project
+func (c *gitlabClient) projectPath() string {
+	return "/projects/" + url.PathEscape(c.repo.FullName())
+}
+
+// Name identifies the forge in messages
+func (c *gitlabClient) Name() string {
+	return "GitLab"
+}
+
+// CreatePullRequest opens a merge request
+func (c *gitlabClient) CreatePullRequest(ctx context.Context, req NewPullRequest) (PullRequest, error) {
+	body := map[string]string{
+		"title":         req.Title,
+		"description":   req.Body,
+		"source_branch": req.Head,
+		"target_branch": req.Base,
+	}
+
+	var created gitlabMergeRequest
+	if err := c.api.do(ctx, http.MethodPost, c.projectPath()+"/merge_requests", body, &created); err != nil {
+		return PullRequest{}, err
+	}
+	return created.pullRequest(), nil
+}
+
+// GetPullRequest looks up a merge request with its approvals and head pipeline
+func (c *gitlabClient) GetPullRequest(ctx context.Context, number int) (PullRequest, error) {
+	var fetched gitlabMergeRequest
+	path := fmt.Sprintf("%s/merge_requests/%d", c.projectPath(), number)
+	if err := c.api.do(ctx, http.MethodGet, path, nil, &fetched); err != nil {
+		return PullRequest{}, err
+	}
+	pr := fetched.pullRequest()
+	if pr.State != StateOpen {
+		return pr, nil
+	}
+
+	var approvals struct {
+		ApprovedBy []struct{} `json:"approved_by"`
+	}
+	if err := c.api.do(ctx, http.MethodGet, path+"/approvals", nil, &approvals); err != nil {
+		return PullRequest{}, err
+	}
+	// Reviewers stay assigned after approving, so an approval wins over a pending request
+	switch {
+	case fetched.DetailedMergeStatus == "requested_changes":
+		pr.Review = ReviewChangesRequested
+	case len(approvals.ApprovedBy) > 0:
+		pr.Review = ReviewApproved
+	case len(fetched.Reviewers) > 0:
+		pr.Review = ReviewRequested
+	}
+
+	if fetched.HeadPipeline != nil {
+		switch fetched.HeadPipeline.Status {
+		case "success":
+			pr.Checks = ChecksSuccess
+		case "failed", "canceled":
+			pr.Checks = ChecksFailure
+		case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+			pr.Checks = ChecksPending
+		}
+	}
+	return pr, nil
+}
+
+// ReviewThreads lists the unresolved discussions of a merge request
+func (c *gitlabClient) ReviewThreads(ctx context.Context, number int) ([]ReviewThread, error) {
+	var discussions []struct {
+		Notes []struct {
+			Author struct {
+				Username string `json:"username"`
+			} `json:"author"`
+			Body       string `json:"body"`
+			System     bool   `json:"system"`
+			Resolvable bool   `json:"resolvable"`
+			Resolved   bool   `json:"resolved"`
+			Position   *struct {
+				NewPath string `json:"new_path"`
+				NewLine int    `json:"new_line"`
+				OldPath string `json:"old_path"`
+				OldLine int    `json:"old_line"`
+			} `json:"position"`
+		} `json:"notes"`
+	}
+	path := fmt.Sprintf("%s/merge_requests/%d/discussions?per_page=100", c.projectPath(), number)
+	if err := c.api.do(ctx, http.MethodGet, path, nil, &discussions); err != nil {
+		return nil, err
+	}
+
+	var threads []ReviewThread
+	for _, discussion := range discussions {
+		if len(discussion.Notes) == 0 {
+			continue
+		}
+		first := discussion.Notes[0]
+		if first.System || !first.Resolvable || first.Resolved {
+			continue
+		}
+
+		var thread ReviewThread
+		if position := first.Position; position != nil {
+			thread.Path, thread.Line = position.NewPath, position.NewLine
+			if thread.Line == 0 {
+				// Comments on removed lines only have an old position
+				thread.Path, thread.Line = position.OldPath, position.OldLine
+			}
+		}
+		for _, note := range discussion.Notes {
+			if !note.System {
+				thread.Comments = append(thread.Comments, ReviewComment{Author: note.Author.Username, Body: note.Body})
+			}
+		}
+		threads = append(threads, thread)
+	}
+	return threads, nil
+}
+
+// gitlabIssue is the part of a GitLab issue that is used
+type gitlabIssue struct {
+	IID         int      `json:"iid"`
+	ProjectID   int      `json:"project_id"`
+	WebURL      string   `json:"web_url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+}
+
+// issue converts a GitLab issue
+func (i gitlabIssue) issue() Issue {
+	return Issue{Number: i.IID, URL: i.WebURL, Title: i.Title, Body: i.Description, Labels: i.Labels}
+}
+
+// ListIssues lists the open issues that have all of the given labels, with the issues of the
+// same project they are linked as blocked by
+func (c *gitlabClient) ListIssues(ctx context.Context, labels []string) ([]Issue, error) {
+	const perPage = 100
+	query := url.Values{"state": {"opened"}, "per_page": {fmt.Sprint(perPage)}}
+	if len(labels) > 0 {
+		query.Set("labels", strings.Join(labels, ","))
+	}
+
+	var issues []Issue
+	for page := 1; page <= maxPages; page++ {
+		query.Set("page", fmt.Sprint(page))
+		var listed []gitlabIssue
+		if err := c.api.do(ctx, http.MethodGet, c.projectPath()+"/issues?"+query.Encode(), nil, &listed); err != nil {
+			return nil, err
+		}
+
+		for _, listedIssue := range listed {
+			issue := listedIssue.issue()
+			var links []struct {
+				IID       int    `json:"iid"`
+				ProjectID int    `json:"project_id"`
+				LinkType  string `json:"link_type"` // "relates_to", "blocks" or "is_blocked_by"
+			}
+			linksPath := fmt.Sprintf("%s/issues/%d/links", c.projectPath(), listedIssue.IID)
+			if err := c.api.do(ctx, http.MethodGet, linksPath, nil, &links); err != nil {
+				return nil, err
+			}
+			for _, link := range links {
+				if link.LinkType == "is_blocked_by" && link.ProjectID == listedIssue.ProjectID {
+					issue.BlockedBy = append(issue.BlockedBy, link.IID)
+				}
+			}
+			issues = append(issues, issue)
+		}
+		if len(listed) < perPage {
+			break
+		}
+	}
+	return issues, nil
+}
+
+// CreateIssue opens an issue. Labels that do not exist yet are created by GitLab.
+func (c *gitlabClient) CreateIssue(ctx context.Context, req NewIssue) (Issue, error) {
+	body := map[string]string{
+		"title":       req.Title,
+		"description": req.Body,
+		"labels":      strings.Join(req.Labels, ","),
+	}
+
+	var created gitlabIssue
+	if err := c.api.do(ctx, http.MethodPost, c.projectPath()+"/issues", body, &created); err != nil {
+		return Issue{}, err
+	}
+	return created.issue(), nil
+}
@@ -0,0 +1,170 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+	defaultAnthropicModel   = "claude-3-5-haiku-latest"
+	anthropicVersion        = "2023-06-01"
+)
+
+// anthropicGenerator talks to the Anthropic Messages API
+type anthropicGenerator struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// anthropicRequest is the body of a Messages API request
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	ToolChoice  *anthropicChoice   `json:"tool_choice,omitempty"`
+}
+
+// anthropicTool is a tool the model may call; its input schema doubles as an output schema
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// anthropicChoice forces the model to call a specific tool
+type anthropicChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// anthropicMessage is a user or assistant message of a Messages API request
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// anthropicResponse is the part of a Messages API response that is used
+type anthropicResponse struct {
+	Content []struct {
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// newAnthropicGenerator creates a generator for the Anthropic API
+func newAnthropicGenerator(apiKey string, config ProviderConfig) *anthropicGenerator {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultAnthropicBaseURL
+	}
+
+	model := config.Model
+	if model == "" {
+		model = defaultAnthropicModel
+	}
+
+	return &anthropicGenerator{
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		client:  http.DefaultClient,
+	}
+}
+
+// Name identifies the provider in error messages
+func (g *anthropicGenerator) Name() string {
+	return "Anthropic"
+}
+
+// Complete sends the conversation to the Messages API. System messages are passed as the
+// system prompt, since the API does not accept them in the message list. A schema is enforced
+// by forcing the model to call a tool with that input schema and returning the tool input.
+func (g *anthropicGenerator) Complete(ctx context.Context, req Request) (string, error) {
+	body := anthropicRequest{
+		Model:       g.model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	}
+	if req.Schema != nil {
+		body.Tools = []anthropicTool{{Name: req.Schema.Name, Description: req.Schema.Description, InputSchema: req.Schema.Schema}}
+		body.ToolChoice = &anthropicChoice{Type: "tool", Name: req.Schema.Name}
+	}
+
+	var system []string
+	for _, message := range req.Messages {
+		if message.Role == RoleSystem {
+			system = append(system, message.Content)
+			continue
+		}
+		body.Messages = append(body.Messages, anthropicMessage{Role: message.Role, Content: message.Content})
+	}
+	body.System = strings.Join(system, "\n\n")
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/v1/messages", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", g.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed anthropicResponse
+	if err := json.Unmarshal(respData, &parsed); err != nil {
+		return "", fmt.Errorf("unexpected response (status %d): %s", resp.StatusCode, strings.TrimSpace(string(respData)))
+	}
+	if parsed.Error != nil {
+		return "", fmt.Errorf("%s: %s", parsed.Error.Type, parsed.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var text []string
+	for _, block := range parsed.Content {
+		switch block.Type {
+		case "text":
+			text = append(text, block.Text)
+		case "tool_use":
+			if req.Schema != nil {
+				return string(block.Input), nil
+			}
+		}
+	}
+	if len(text) == 0 {
+		return "", fmt.Errorf("no response received from Anthropic")
+	}
+
+	return strings.Join(text, ""), nil
+}
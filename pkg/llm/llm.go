@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Message roles
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Providers that can generate tasks
+const (
+	ProviderOpenAI           = "openai"
+	ProviderAnthropic        = "anthropic"
+	ProviderAzureOpenAI      = "azure"
+	ProviderOpenAICompatible = "openai-compatible" // Ollama, vLLM and other servers exposing the OpenAI API
+)
+
+// Message is one message of a chat conversation
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request is a chat conversation to send to a model
+type Request struct {
+	Messages    []Message
+	MaxTokens   int
+	Temperature float32
+	Schema      *Schema // Constrains the reply to JSON matching the schema where the provider supports it
+}
+
+// Schema describes the JSON document a model should reply with
+type Schema struct {
+	Name        string          // Identifier of the schema, e.g. "task_list"
+	Description string          // What the document contains
+	Schema      json.RawMessage // JSON Schema of the document; the root must be an object
+}
+
+// TaskGenerator sends a chat conversation to a language model and returns its reply
+type TaskGenerator interface {
+	// Name identifies the provider in error messages, e.g. "OpenAI"
+	Name() string
+	// Complete returns the text of the model's reply to the conversation
+	Complete(ctx context.Context, req Request) (string, error)
+}
+
+// ProviderConfig selects and configures the provider used to generate tasks. API keys are
+// never stored in it; they are read from the environment variable named by APIKeyEnv.
+type ProviderConfig struct {
+	Provider   string `json:"provider,omitempty"`   // One of the Provider constants; empty means OpenAI
+	Model      string `json:"model,omitempty"`      // Model name, or the deployment name for Azure
+	BaseURL    string `json:"baseUrl,omitempty"`    // API endpoint; required for Azure and OpenAI-compatible servers
+	APIKeyEnv  string `json:"apiKeyEnv,omitempty"`  // Environment variable holding the API key; defaults per provider
+	APIVersion string `json:"apiVersion,omitempty"` // Azure API version
+	// StructuredOutputs sends schemas as strict json_schema response formats to Azure and
+	// OpenAI-compatible servers. Leave it off for servers or API versions that reject them;
+	// replies are then parsed without the format. OpenAI always uses structured outputs.
+	StructuredOutputs bool `json:"structuredOutputs,omitempty"`
+}
+
+// defaultAPIKeyEnv is the environment variable each provider reads its key from by default
+var defaultAPIKeyEnv = map[string]string{
+	ProviderOpenAI:           "OPENAI_API_KEY",
+	ProviderAnthropic:        "ANTHROPIC_API_KEY",
+	ProviderAzureOpenAI:      "AZURE_OPENAI_API_KEY",
+	ProviderOpenAICompatible: "OLLAMA_API_KEY",
+}
+
+// provider returns the configured provider, defaulting to OpenAI
+func (c ProviderConfig) provider() string {
+	if c.Provider == "" {
+		return ProviderOpenAI
+	}
+	return c.Provider
+}
+
+// Validate checks that the config names a known provider and has the fields it requires
+func (c ProviderConfig) Validate() error {
+	switch c.provider() {
+	case ProviderOpenAI, ProviderAnthropic:
+		return nil
+	case ProviderAzureOpenAI:
+		if c.BaseURL == "" {
+			return fmt.Errorf("Azure OpenAI requires the resource endpoint as base URL")
+		}
+		if c.Model == "" {
+			return fmt.Errorf("Azure OpenAI requires the deployment name as model")
+		}
+		return nil
+	case ProviderOpenAICompatible:
+		if c.BaseURL == "" {
+			return fmt.Errorf("OpenAI-compatible providers require a base URL")
+		}
+		if c.Model == "" {
+			return fmt.Errorf("OpenAI-compatible providers require a model")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown provider: %s", c.Provider)
+	}
+}
+
+// apiKey reads the provider's API key from the environment
+func (c ProviderConfig) apiKey() (string, error) {
+	envName := c.APIKeyEnv
+	if envName == "" {
+		envName = defaultAPIKeyEnv[c.provider()]
+	}
+
+	key := os.Getenv(envName)
+	// Local OpenAI-compatible servers such as Ollama usually need no key
+	if key == "" && c.provider() != ProviderOpenAICompatible {
+		return "", fmt.Errorf("%s environment variable is not set", envName)
+	}
+	return key, nil
+}
+
+// NewTaskGenerator creates the TaskGenerator for a provider config
+func NewTaskGenerator(config ProviderConfig) (TaskGenerator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	apiKey, err := config.apiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.provider() {
+	case ProviderAnthropic:
+		return newAnthropicGenerator(apiKey, config), nil
+	case ProviderAzureOpenAI:
+		return newAzureOpenAIGenerator(apiKey, config), nil
+	case ProviderOpenAICompatible:
+		return newOpenAICompatibleGenerator(apiKey, config), nil
+	default:
+		return newOpenAIGenerator(apiKey, config), nil
+	}
+}
@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderConfigValidate(t *testing.T) {
+	if err := (ProviderConfig{}).Validate(); err != nil {
+		t.Errorf("Expected the default OpenAI config to be valid, got: %v", err)
+	}
+	if err := (ProviderConfig{Provider: ProviderAzureOpenAI, Model: "gpt-4o"}).Validate(); err == nil {
+		t.Error("Expected Azure without endpoint to be rejected")
+	}
+	if err := (ProviderConfig{Provider: ProviderOpenAICompatible, BaseURL: "http://localhost:11434/v1"}).Validate(); err == nil {
+		t.Error("Expected OpenAI-compatible provider without model to be rejected")
+	}
+	if err := (ProviderConfig{Provider: "gemini"}).Validate(); err == nil {
+		t.Error("Expected unknown provider to be rejected")
+	}
+}
+
+func TestNewTaskGeneratorRequiresAPIKey(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if _, err := NewTaskGenerator(ProviderConfig{Provider: ProviderAnthropic}); err == nil {
+		t.Error("Expected missing API key to be rejected")
+	}
+
+	t.Setenv("OLLAMA_API_KEY", "")
+	if _, err := NewTaskGenerator(ProviderConfig{Provider: ProviderOpenAICompatible, BaseURL: "http://localhost:11434/v1", Model: "llama3"}); err != nil {
+		t.Errorf("Expected OpenAI-compatible servers to work without a key, got: %v", err)
+	}
+}
+
+func TestAnthropicGeneratorComplete(t *testing.T) {
+	var received anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("Unexpected request %s with key %q", r.URL.Path, r.Header.Get("x-api-key"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		w.Write([]byte(`{"content":[{"type":"text","text":"[]"}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	generator, err := NewTaskGenerator(ProviderConfig{Provider: ProviderAnthropic, BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Expected generator, got: %v", err)
+	}
+
+	text, err := generator.Complete(context.Background(), Request{
+		Messages:  []Message{{Role: RoleSystem, Content: "Be brief"}, {Role: RoleUser, Content: "List tasks"}},
+		MaxTokens: 100,
+	})
+	if err != nil || text != "[]" {
+		t.Fatalf("Expected reply \"[]\", got %q (%v)", text, err)
+	}
+	if received.System != "Be brief" || len(received.Messages) != 1 || received.Messages[0].Role != RoleUser {
+		t.Errorf("Expected system prompt to be split from messages, got %+v", received)
+	}
+	if received.Model != defaultAnthropicModel {
+		t.Errorf("Expected default model, got %s", received.Model)
+	}
+}
+
+func TestOpenAICompatibleGeneratorComplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/v1/chat/completions" || body.Model != "llama3" {
+			t.Errorf("Unexpected request %s for model %s", r.URL.Path, body.Model)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"[]"}}]}`))
+	}))
+	defer server.Close()
+
+	generator, err := NewTaskGenerator(ProviderConfig{Provider: ProviderOpenAICompatible, BaseURL: server.URL + "/v1", Model: "llama3"})
+	if err != nil {
+		t.Fatalf("Expected generator, got: %v", err)
+	}
+
+	text, err := generator.Complete(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "List tasks"}}})
+	if err != nil || text != "[]" {
+		t.Errorf("Expected reply \"[]\", got %q (%v)", text, err)
+	}
+}
+
+func TestAnthropicGeneratorReturnsToolInputForSchema(t *testing.T) {
+	var received anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"content":[{"type":"tool_use","name":"task_list","input":{"tasks":[]}}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	generator, err := NewTaskGenerator(ProviderConfig{Provider: ProviderAnthropic, BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Expected generator, got: %v", err)
+	}
+
+	schema := &Schema{Name: "task_list", Schema: json.RawMessage(`{"type":"object"}`)}
+	text, err := generator.Complete(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "List tasks"}}, Schema: schema})
+	if err != nil || text != `{"tasks":[]}` {
+		t.Fatalf("Expected the tool input, got %q (%v)", text, err)
+	}
+	if len(received.Tools) != 1 || received.ToolChoice == nil || received.ToolChoice.Name != "task_list" {
+		t.Errorf("Expected the schema tool to be forced, got %+v", received)
+	}
+}
+
+func TestAzureGeneratorSendsSchemaOnlyWithStructuredOutputs(t *testing.T) {
+	var received map[string]json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/tasks/chat/completions" {
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+		received = nil
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{}"}}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("AZURE_OPENAI_API_KEY", "test-key")
+	request := Request{
+		Messages: []Message{{Role: RoleUser, Content: "List tasks"}},
+		Schema:   &Schema{Name: "task_list", Schema: json.RawMessage(`{"type":"object"}`)},
+	}
+
+	generator, err := NewTaskGenerator(ProviderConfig{Provider: ProviderAzureOpenAI, BaseURL: server.URL, Model: "tasks"})
+	if err != nil {
+		t.Fatalf("Expected generator, got: %v", err)
+	}
+	if _, err := generator.Complete(context.Background(), request); err != nil {
+		t.Fatalf("Expected a reply, got: %v", err)
+	}
+	if format, ok := received["response_format"]; ok {
+		t.Errorf("Expected no response format for the default Azure API version, got %s", format)
+	}
+
+	generator, _ = NewTaskGenerator(ProviderConfig{Provider: ProviderAzureOpenAI, BaseURL: server.URL, Model: "tasks", APIVersion: "2024-08-01-preview", StructuredOutputs: true})
+	if _, err := generator.Complete(context.Background(), request); err != nil {
+		t.Fatalf("Expected a reply, got: %v", err)
+	}
+	var format struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(received["response_format"], &format); format.Type != "json_schema" {
+		t.Errorf("Expected a json_schema response format with structured outputs, got %s", received["response_format"])
+	}
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// openAIGenerator talks to OpenAI or any server implementing its chat completions API
+type openAIGenerator struct {
+	name   string
+	client *openai.Client
+	model  string
+	// structuredOutputs sends schemas as json_schema response formats, which older Azure API
+	// versions and many self-hosted servers reject
+	structuredOutputs bool
+}
+
+// newOpenAIGenerator creates a generator for the OpenAI API
+func newOpenAIGenerator(apiKey string, config ProviderConfig) *openAIGenerator {
+	clientConfig := openai.DefaultConfig(apiKey)
+	if config.BaseURL != "" {
+		clientConfig.BaseURL = config.BaseURL
+	}
+
+	model := config.Model
+	if model == "" {
+		model = openai.GPT4oMini
+	}
+
+	return &openAIGenerator{name: "OpenAI", client: openai.NewClientWithConfig(clientConfig), model: model, structuredOutputs: true}
+}
+
+// newAzureOpenAIGenerator creates a generator for an Azure OpenAI deployment
+func newAzureOpenAIGenerator(apiKey string, config ProviderConfig) *openAIGenerator {
+	clientConfig := openai.DefaultAzureConfig(apiKey, config.BaseURL)
+	if config.APIVersion != "" {
+		clientConfig.APIVersion = config.APIVersion
+	}
+
+	return &openAIGenerator{
+		name:              "Azure OpenAI",
+		client:            openai.NewClientWithConfig(clientConfig),
+		model:             config.Model,
+		structuredOutputs: config.StructuredOutputs,
+	}
+}
+
+// newOpenAICompatibleGenerator creates a generator for a self-hosted OpenAI-compatible server
+func newOpenAICompatibleGenerator(apiKey string, config ProviderConfig) *openAIGenerator {
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.BaseURL = config.BaseURL
+
+	return &openAIGenerator{
+		name:              fmt.Sprintf("OpenAI-compatible server at %s", config.BaseURL),
+		client:            openai.NewClientWithConfig(clientConfig),
+		model:             config.Model,
+		structuredOutputs: config.StructuredOutputs,
+	}
+}
+
+// Name identifies the provider in error messages
+func (g *openAIGenerator) Name() string {
+	return g.name
+}
+
+// Complete sends the conversation as a chat completion request. A schema is enforced with
+// the structured outputs response format where the server supports it; otherwise the request
+// has no response format and callers parse the JSON out of the reply.
+func (g *openAIGenerator) Complete(ctx context.Context, req Request) (string, error) {
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages))
+	for _, message := range req.Messages {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    message.Role,
+			Content: message.Content,
+		})
+	}
+
+	chatReq := openai.ChatCompletionRequest{
+		Model:       g.model,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	}
+	if req.Schema != nil && g.structuredOutputs {
+		chatReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:        req.Schema.Name,
+				Description: req.Schema.Description,
+				Schema:      req.Schema.Schema,
+				Strict:      true,
+			},
+		}
+	}
+
+	resp, err := g.client.CreateChatCompletion(ctx, chatReq)
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response received from %s", g.name)
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"specprint/pkg/forge"
+)
+
+// Event emitted to the frontend when syncing changed the pull request or status of a task
+const PullRequestStatusEvent = "pullrequest:status"
+
+// defaultPullRequestSyncInterval is used when Settings.PullRequestSyncSeconds is 0
+const defaultPullRequestSyncInterval = 2 * time.Minute
+
+// PullRequestStatusChange describes how syncing its pull request changed a task
+type PullRequestStatusChange struct {
+	Workspace   string            `json:"workspace"`
+	TaskID      int               `json:"taskId"`
+	PullRequest forge.PullRequest `json:"pullRequest"`
+	From        TaskStatus        `json:"from"`
+	To          TaskStatus        `json:"to"` // Equal to From when only the pull request changed
+}
+
+// PullRequestSyncResult represents the result of syncing the pull requests of a workspace
+type PullRequestSyncResult struct {
+	Success bool                      `json:"success"`
+	Message string                    `json:"message"`
+	Changes []PullRequestStatusChange `json:"changes,omitempty"`
+}
+
+// pullRequestTaskStatus returns the status a task moves to for the state of its pull request,
+// and the error to record when the pull request needs attention
+func pullRequestTaskStatus(pr forge.PullRequest) (TaskStatus, string) {
+	switch {
+	case pr.State == forge.StateMerged:
+		return TaskStatusMerged, ""
+	case pr.State == forge.StateClosed:
+		return TaskStatusTodo, ""
+	case pr.Checks == forge.ChecksFailure:
+		return TaskStatusFailed, fmt.Sprintf("Checks failed on pull request #%d", pr.Number)
+	case pr.Review == forge.ReviewChangesRequested:
+		return TaskStatusInProgress, ""
+	case pr.Review == forge.ReviewApproved:
+		return TaskStatusDone, ""
+	default:
+		return TaskStatusReview, ""
+	}
+}
+
+// syncPullRequests looks up the open pull requests of a workspace's tasks and moves each task
+// to the status matching its pull request. The forge is the source of truth, so statuses change
+// without the transition rules of MoveTask; running tasks are left alone. The changes are
+// returned along with the errors of any pull requests that could not be looked up.
+func (a *App) syncPullRequests(workspaceName string) ([]PullRequestStatusChange, error) {
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %v", err)
+	}
+	var tracked []Task
+	for _, task := range board.Tasks {
+		if task.PullRequest != nil && task.PullRequest.State == forge.StateOpen && task.Status != TaskStatusRunning {
+			tracked = append(tracked, task)
+		}
+	}
+	if len(tracked) == 0 {
+		return nil, nil
+	}
+
+	client, err := a.workspaceForge(targetWorkspace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query the forge before taking the store lock, as it may be slow. A pull request that
+	// cannot be looked up leaves its task as it is, without holding up the others.
+	fetched := make(map[int]forge.PullRequest)
+	var lookupErrs []error
+	for _, task := range tracked {
+		ctx, cancel := context.WithTimeout(context.Background(), forgeRequestTimeout)
+		pr, err := client.GetPullRequest(ctx, task.PullRequest.Number)
+		cancel()
+		if err != nil {
+			lookupErrs = append(lookupErrs, fmt.Errorf("failed to look up pull request #%d of task %d on %s: %v", task.PullRequest.Number, task.ID, client.Name(), err))
+			continue
+		}
+		fetched[task.ID] = pr
+	}
+
+	var changes []PullRequestStatusChange
+	_, err = a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		changes = nil
+		for _, trackedTask := range tracked {
+			taskID := trackedTask.ID
+			pr, ok := fetched[taskID]
+			if !ok {
+				continue
+			}
+			index := board.findTask(taskID)
+			if index < 0 {
+				continue
+			}
+			task := &board.Tasks[index]
+			if task.Status == TaskStatusRunning || task.PullRequest == nil || task.PullRequest.Number != pr.Number {
+				continue
+			}
+
+			status, reason := pullRequestTaskStatus(pr)
+			if *task.PullRequest == pr && task.Status == status {
+				continue
+			}
+
+			changes = append(changes, PullRequestStatusChange{
+				Workspace:   workspaceName,
+				TaskID:      taskID,
+				PullRequest: pr,
+				From:        task.Status,
+				To:          status,
+			})
+			updated := pr
+			task.PullRequest = &updated
+			if task.Status != status {
+				task.Status = status
+				task.LastError = reason
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tasks: %v", err)
+	}
+
+	for _, change := range changes {
+		a.emitEvent(PullRequestStatusEvent, change)
+	}
+	return changes, errors.Join(lookupErrs...)
+}
+
+// SyncPullRequests updates the tasks of a workspace from the state of their pull requests now,
+// instead of waiting for the background sync
+func (a *App) SyncPullRequests(workspaceName string) PullRequestSyncResult {
+	changes, err := a.syncPullRequests(workspaceName)
+	if err != nil {
+		message := err.Error()
+		if len(changes) > 0 {
+			message = fmt.Sprintf("Updated %d task(s) from their pull requests, but %v", len(changes), err)
+		}
+		return PullRequestSyncResult{
+			Success: false,
+			Message: message,
+			Changes: changes,
+		}
+	}
+
+	return PullRequestSyncResult{
+		Success: true,
+		Message: fmt.Sprintf("Updated %d task(s) from their pull requests", len(changes)),
+		Changes: changes,
+	}
+}
+
+// pullRequestSyncInterval returns how often pull requests are synced, and false if the
+// background sync is turned off
+func (a *App) pullRequestSyncInterval() (time.Duration, bool) {
+	settings, err := a.settings.LoadGlobal()
+	if err != nil || settings.PullRequestSyncSeconds == 0 {
+		return defaultPullRequestSyncInterval, true
+	}
+	if settings.PullRequestSyncSeconds < 0 {
+		return defaultPullRequestSyncInterval, false
+	}
+	return time.Duration(settings.PullRequestSyncSeconds) * time.Second, true
+}
+
+// pollPullRequests syncs the pull requests of every workspace until ctx is done. The settings
+// are re-read each round, so changing the interval needs no restart.
+func (a *App) pollPullRequests(ctx context.Context) {
+	interval, _ := a.pullRequestSyncInterval()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		var enabled bool
+		interval, enabled = a.pullRequestSyncInterval()
+		if !enabled {
+			continue
+		}
+		// GetWorkspaces rewrites workspaces.json, which must not race with the frontend
+		workspaces, err := a.listWorkspaces()
+		if err != nil {
+			fmt.Printf("Warning: Failed to list workspaces to sync pull requests: %v\n", err)
+			continue
+		}
+		for _, workspace := range workspaces {
+			if _, err := a.syncPullRequests(workspace.Name); err != nil {
+				fmt.Printf("Warning: Failed to sync pull requests of workspace %s: %v\n", workspace.Name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"specprint/pkg/forge"
+)
+
+func TestSyncPullRequestsMovesTasks(t *testing.T) {
+	responses := map[string]string{
+		"/repos/acme/shop/pulls/1":               `{"number":1,"state":"closed","merged":true}`,
+		"/repos/acme/shop/pulls/2":               `{"number":2,"state":"open","head":{"sha":"b2"}}`,
+		"/repos/acme/shop/pulls/2/reviews":       `[{"user":{"login":"bob"},"state":"CHANGES_REQUESTED"}]`,
+		"/repos/acme/shop/commits/b2/status":     `{"state":"success","total_count":1}`,
+		"/repos/acme/shop/commits/b2/check-runs": `{"check_runs":[]}`,
+		"/repos/acme/shop/pulls/3":               `{"number":3,"state":"open","head":{"sha":"c3"}}`,
+		"/repos/acme/shop/pulls/3/reviews":       `[]`,
+		"/repos/acme/shop/commits/c3/status":     `{"state":"pending","total_count":0}`,
+		"/repos/acme/shop/commits/c3/check-runs": `{"check_runs":[{"status":"completed","conclusion":"failure"}]}`,
+	}
+	app := setupForgeWorkspace(t, func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(response))
+	})
+
+	open := func(number int) *forge.PullRequest {
+		return &forge.PullRequest{Number: number, State: forge.StateOpen}
+	}
+	app.SaveTasks("demo", []Task{
+		{ID: 1, Title: "Merged", Status: TaskStatusReview, PullRequest: open(1)},
+		{ID: 2, Title: "Changes requested", Status: TaskStatusReview, PullRequest: open(2)},
+		{ID: 3, Title: "CI failing", Status: TaskStatusReview, PullRequest: open(3)},
+		{ID: 4, Title: "Running", Status: TaskStatusReview, PullRequest: open(4)},
+		{ID: 5, Title: "No pull request", Status: TaskStatusTodo},
+	})
+	if err := app.startTaskRun("demo", 4, "Running", "", "", ""); err != nil {
+		t.Fatalf("Expected task 4 to start, got: %v", err)
+	}
+
+	result := app.SyncPullRequests("demo")
+	if !result.Success || len(result.Changes) != 3 {
+		t.Fatalf("Expected three tasks to change, got %+v", result)
+	}
+
+	board, _ := app.tasks.Load("demo")
+	expected := map[int]TaskStatus{1: TaskStatusMerged, 2: TaskStatusInProgress, 3: TaskStatusFailed, 4: TaskStatusRunning, 5: TaskStatusTodo}
+	for _, task := range board.Tasks {
+		if task.Status != expected[task.ID] {
+			t.Errorf("Expected task %d to be %s, got %s", task.ID, expected[task.ID], task.Status)
+		}
+	}
+	if task := board.Tasks[2]; task.PullRequest.Checks != forge.ChecksFailure || task.LastError != "Checks failed on pull request #3" {
+		t.Errorf("Expected the failing checks to be recorded, got %+v", task)
+	}
+	if board.Tasks[1].PullRequest.Review != forge.ReviewChangesRequested {
+		t.Errorf("Expected the review state to be recorded, got %+v", board.Tasks[1].PullRequest)
+	}
+
+	// Merged pull requests are no longer tracked and unchanged ones are not reported again
+	again := app.SyncPullRequests("demo")
+	if !again.Success || len(again.Changes) != 0 {
+		t.Errorf("Expected no further changes, got %+v", again)
+	}
+}
+
+func TestPullRequestTaskStatus(t *testing.T) {
+	tests := map[TaskStatus]forge.PullRequest{
+		TaskStatusReview: {State: forge.StateOpen, Review: forge.ReviewRequested, Checks: forge.ChecksPending},
+		TaskStatusDone:   {State: forge.StateOpen, Review: forge.ReviewApproved, Checks: forge.ChecksSuccess},
+		TaskStatusTodo:   {State: forge.StateClosed},
+	}
+	for expected, pr := range tests {
+		if status, _ := pullRequestTaskStatus(pr); status != expected {
+			t.Errorf("Expected %+v to move the task to %s, got %s", pr, expected, status)
+		}
+	}
+}
+
+func TestSyncPullRequestsContinuesAfterLookupError(t *testing.T) {
+	app := setupForgeWorkspace(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/shop/pulls/2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"number":2,"state":"closed","merged":true}`))
+	})
+
+	open := func(number int) *forge.PullRequest {
+		return &forge.PullRequest{Number: number, State: forge.StateOpen}
+	}
+	app.SaveTasks("demo", []Task{
+		{ID: 1, Title: "Unreachable", Status: TaskStatusReview, PullRequest: open(1)},
+		{ID: 2, Title: "Merged", Status: TaskStatusReview, PullRequest: open(2)},
+	})
+
+	result := app.SyncPullRequests("demo")
+	if result.Success || !strings.Contains(result.Message, "pull request #1 of task 1") {
+		t.Errorf("Expected the failed lookup to be reported, got %+v", result)
+	}
+	if len(result.Changes) != 1 || result.Changes[0].TaskID != 2 {
+		t.Fatalf("Expected task 2 to change despite the failed lookup, got %+v", result.Changes)
+	}
+
+	board, _ := app.tasks.Load("demo")
+	if board.Tasks[0].Status != TaskStatusReview || board.Tasks[1].Status != TaskStatusMerged {
+		t.Errorf("Expected task 1 in review and task 2 merged, got %s and %s", board.Tasks[0].Status, board.Tasks[1].Status)
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"specprint/pkg/llm"
+)
+
+// Categories of PRD review findings
+const (
+	FindingMissingSection = "missing-section"
+	FindingAmbiguous      = "ambiguous"
+	FindingContradiction  = "contradiction"
+)
+
+// prdDraftRepoChars bounds the repository summary sent along with a PRD draft request
+const prdDraftRepoChars = 24000
+
+// PRDFinding is one problem found when reviewing a PRD
+type PRDFinding struct {
+	Category   string `json:"category"`
+	Severity   string `json:"severity"` // "high", "medium" or "low"
+	Section    string `json:"section"`  // Heading the finding refers to, or the missing section's name
+	Quote      string `json:"quote"`    // The PRD text at fault; empty for missing sections
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+// PRDReviewResult represents the result of reviewing a PRD
+type PRDReviewResult struct {
+	Success  bool         `json:"success"`
+	Message  string       `json:"message"`
+	Summary  string       `json:"summary,omitempty"`
+	Findings []PRDFinding `json:"findings,omitempty"`
+}
+
+// PRDDraftResult represents the result of expanding a feature idea into a PRD
+type PRDDraftResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Content string `json:"content,omitempty"`
+}
+
+// requiredPRDSection is a section every PRD should have and the headings that count as it
+type requiredPRDSection struct {
+	name     string
+	keywords []string
+	why      string
+}
+
+// requiredPRDSections are checked without the model, so they are reported reliably
+var requiredPRDSections = []requiredPRDSection{
+	{
+		name:     "Acceptance Criteria",
+		keywords: []string{"acceptance criteria", "acceptance", "definition of done"},
+		why:      "Without acceptance criteria there is no way to tell when a task is done.",
+	},
+	{
+		name:     "Non-Functional Requirements",
+		keywords: []string{"non-functional", "nonfunctional", "non functional", "quality attributes"},
+		why:      "Performance, security, reliability and accessibility expectations are not stated.",
+	},
+	{
+		name:     "Out of Scope",
+		keywords: []string{"out of scope", "out-of-scope", "non-goals", "not in scope"},
+		why:      "Without explicit limits, generated tasks tend to cover more than intended.",
+	},
+}
+
+// prdReviewSchema is the JSON schema of the review the model is asked for
+var prdReviewSchema = &llm.Schema{
+	Name:        "prd_review",
+	Description: "Problems found in a PRD draft",
+	Schema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "summary": {"type": "string"},
+    "findings": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "category": {"type": "string", "enum": ["missing-section", "ambiguous", "contradiction"]},
+          "severity": {"type": "string", "enum": ["high", "medium", "low"]},
+          "section": {"type": "string"},
+          "quote": {"type": "string"},
+          "message": {"type": "string"},
+          "suggestion": {"type": "string"}
+        },
+        "required": ["category", "severity", "section", "quote", "message", "suggestion"],
+        "additionalProperties": false
+      }
+    }
+  },
+  "required": ["summary", "findings"],
+  "additionalProperties": false
+}`),
+}
+
+// prdReviewSystemPrompt instructs the model to review a PRD draft
+const prdReviewSystemPrompt = `You are an experienced product manager reviewing a Product Requirements Document (PRD) before it is broken down into implementation tasks.
+
+Report:
+- missing-section: sections a PRD needs but this one lacks, such as acceptance criteria, non-functional requirements (performance, security, accessibility, reliability), out-of-scope items, user roles or error handling
+- ambiguous: requirements that two engineers could implement differently, e.g. vague quantities ("fast", "many"), undefined terms, or unclear actors and triggers
+- contradiction: requirements that cannot all be true at once
+
+For each finding give the category, a severity ("high" if tasks cannot be generated correctly without fixing it), the section heading it refers to (or the name of the missing section), the exact text at fault as quote (empty for missing sections), a short message and a concrete suggestion for rewording or adding text.
+
+Only report real problems; an empty findings list is fine for a good PRD. Return ONLY a JSON object with a "summary" of the PRD's overall quality and a "findings" array.`
+
+// prdDraftSystemPrompt instructs the model to expand a feature idea into a PRD
+const prdDraftSystemPrompt = `You are an experienced product manager writing a Product Requirements Document (PRD) for a feature of an existing codebase.
+
+Expand the feature idea into a complete PRD in markdown with these sections:
+# <Feature name>
+## Overview
+## Goals
+## User Stories
+## Functional Requirements
+## Non-Functional Requirements
+## Acceptance Criteria
+## Out of Scope
+## Technical Notes
+## Open Questions
+
+Ground the PRD in the repository: use its terminology, mention the existing modules, files and dependencies the feature will touch in Technical Notes, and do not propose a stack the repository does not use. Write acceptance criteria as testable statements. Where the idea leaves something open, make a reasonable assumption and list it under Open Questions instead of inventing certainty.
+
+Return ONLY the markdown document.`
+
+// missingPRDSections reports the required sections that none of the PRD's headings cover
+func missingPRDSections(prdContent string) []PRDFinding {
+	var headings []string
+	for _, section := range splitPRDSections(prdContent) {
+		headings = append(headings, strings.ToLower(section.heading))
+	}
+
+	var findings []PRDFinding
+	for _, required := range requiredPRDSections {
+		found := false
+		for _, heading := range headings {
+			for _, keyword := range required.keywords {
+				if strings.Contains(heading, keyword) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			findings = append(findings, PRDFinding{
+				Category:   FindingMissingSection,
+				Severity:   "medium",
+				Section:    required.name,
+				Message:    fmt.Sprintf("The PRD has no %s section. %s", required.name, required.why),
+				Suggestion: fmt.Sprintf("Add a \"## %s\" section.", required.name),
+			})
+		}
+	}
+	return findings
+}
+
+// ReviewPRD reviews a PRD draft for missing sections, ambiguous requirements and contradictions.
+// The workspace selects the task generation provider; it may be empty to use the app settings.
+func (a *App) ReviewPRD(workspaceName, prdContent string) PRDReviewResult {
+	if strings.TrimSpace(prdContent) == "" {
+		return PRDReviewResult{
+			Success: false,
+			Message: "PRD content cannot be empty",
+		}
+	}
+
+	generator, err := a.taskGenerator(workspaceName)
+	if err != nil {
+		return PRDReviewResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	return reviewPRD(generator, prdContent)
+}
+
+// ReviewWorkspaceSpec reviews a spec document of a workspace as saved on disk
+func (a *App) ReviewWorkspaceSpec(workspaceName, specID string) PRDReviewResult {
+	targetWorkspace, err := a.findSpecWorkspace(workspaceName, specID)
+	if err != nil {
+		return PRDReviewResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	content, err := os.ReadFile(specPath(targetWorkspace.Path, specID))
+	if os.IsNotExist(err) {
+		return PRDReviewResult{
+			Success: false,
+			Message: fmt.Sprintf("Workspace '%s' does not have %s", workspaceName, specLabel(specID)),
+		}
+	}
+	if err != nil {
+		return PRDReviewResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to read spec: %v", err),
+		}
+	}
+
+	return a.ReviewPRD(workspaceName, string(content))
+}
+
+// reviewPRD asks the generator to review a PRD and merges its findings with the required
+// sections that are missing. A missing section reported by both is listed once.
+func reviewPRD(generator llm.TaskGenerator, prdContent string) PRDReviewResult {
+	messages := []llm.Message{
+		{
+			Role:    llm.RoleSystem,
+			Content: prdReviewSystemPrompt,
+		},
+		{
+			Role:    llm.RoleUser,
+			Content: fmt.Sprintf("Please review this PRD:\n\n%s", prdContent),
+		},
+	}
+
+	var review struct {
+		Summary  string       `json:"summary"`
+		Findings []PRDFinding `json:"findings"`
+	}
+	for attempt := 0; ; attempt++ {
+		responseContent, err := generator.Complete(context.Background(), llm.Request{
+			Messages:    messages,
+			MaxTokens:   taskGenerationMaxTokens,
+			Temperature: 0.2,
+			Schema:      prdReviewSchema,
+		})
+		if err != nil {
+			return PRDReviewResult{
+				Success: false,
+				Message: fmt.Sprintf("Failed to call %s API: %v", generator.Name(), err),
+			}
+		}
+
+		err = decodeEmbeddedJSON(responseContent, func(raw json.RawMessage) error {
+			review.Summary, review.Findings = "", nil
+			if err := json.Unmarshal(raw, &review); err != nil {
+				return err
+			}
+			if review.Findings == nil {
+				return fmt.Errorf("JSON object has no \"findings\" array")
+			}
+			return nil
+		})
+		if err == nil {
+			break
+		}
+		if attempt >= 1 {
+			return PRDReviewResult{
+				Success: false,
+				Message: fmt.Sprintf("Failed to parse JSON response: %v. Response was: %s", err, responseContent),
+			}
+		}
+		messages = append(messages,
+			llm.Message{Role: llm.RoleAssistant, Content: responseContent},
+			llm.Message{Role: llm.RoleUser, Content: fmt.Sprintf("Your response could not be parsed: %v\n\nReturn the review again as ONLY a JSON object with \"summary\" and \"findings\".", err)},
+		)
+	}
+
+	findings := missingPRDSections(prdContent)
+	reported := make(map[string]bool)
+	for _, finding := range findings {
+		reported[strings.ToLower(finding.Section)] = true
+	}
+	for _, finding := range review.Findings {
+		switch finding.Category {
+		case FindingMissingSection, FindingAmbiguous, FindingContradiction:
+		default:
+			continue
+		}
+		if finding.Category == FindingMissingSection && reported[strings.ToLower(finding.Section)] {
+			continue
+		}
+		switch finding.Severity {
+		case "high", "medium", "low":
+		default:
+			finding.Severity = "medium"
+		}
+		findings = append(findings, finding)
+	}
+
+	return PRDReviewResult{
+		Success:  true,
+		Message:  fmt.Sprintf("Found %d issues in the PRD", len(findings)),
+		Summary:  review.Summary,
+		Findings: findings,
+	}
+}
+
+// DraftPRD expands a short feature idea into a PRD skeleton grounded in the workspace's
+// repository. The draft is returned for editing and not saved.
+func (a *App) DraftPRD(workspaceName, idea string) PRDDraftResult {
+	if strings.TrimSpace(idea) == "" {
+		return PRDDraftResult{
+			Success: false,
+			Message: "Feature idea cannot be empty",
+		}
+	}
+
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
+		return PRDDraftResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	repository, err := describeRepository(targetWorkspace.Path, prdDraftRepoChars)
+	if err != nil {
+		return PRDDraftResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	generator, err := a.taskGenerator(workspaceName)
+	if err != nil {
+		return PRDDraftResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	return draftPRD(generator, idea, repository)
+}
+
+// draftPRD asks the generator to write a PRD for an idea given a summary of the repository
+func draftPRD(generator llm.TaskGenerator, idea, repository string) PRDDraftResult {
+	responseContent, err := generator.Complete(context.Background(), llm.Request{
+		Messages: []llm.Message{
+			{
+				Role:    llm.RoleSystem,
+				Content: prdDraftSystemPrompt,
+			},
+			{
+				Role:    llm.RoleUser,
+				Content: fmt.Sprintf("Repository:\n%s\n\nFeature idea:\n%s", repository, idea),
+			},
+		},
+		MaxTokens:   taskGenerationMaxTokens,
+		Temperature: 0.4,
+	})
+	if err != nil {
+		return PRDDraftResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to call %s API: %v", generator.Name(), err),
+		}
+	}
+
+	content := strings.TrimSpace(stripCodeFence(responseContent))
+	if content == "" {
+		return PRDDraftResult{
+			Success: false,
+			Message: fmt.Sprintf("%s returned an empty PRD", generator.Name()),
+		}
+	}
+
+	return PRDDraftResult{
+		Success: true,
+		Message: "Drafted a PRD from the feature idea",
+		Content: content + "\n",
+	}
+}
+
+// stripCodeFence removes a code fence wrapped around a whole response, such as ```markdown
+func stripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") {
+		return content
+	}
+	trimmed = strings.TrimSuffix(trimmed, "```")
+	if newline := strings.Index(trimmed, "\n"); newline >= 0 {
+		return trimmed[newline+1:]
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReviewPRDMergesMissingSections(t *testing.T) {
+	prd := "# Search\n\n## Requirements\nResults must load fast.\n\n## Out of Scope\nFiltering.\n"
+	generator := &scriptedGenerator{responses: []string{
+		"Here is my review:\n```json\n" + `{"summary":"Short PRD","findings":[
+			{"category":"missing-section","severity":"high","section":"acceptance criteria","quote":"","message":"No criteria","suggestion":"Add them"},
+			{"category":"ambiguous","severity":"urgent","section":"Requirements","quote":"must load fast","message":"Fast is not measurable","suggestion":"Say within 200ms"},
+			{"category":"opinion","severity":"low","section":"","quote":"","message":"I like it","suggestion":""}
+		]}` + "\n```",
+	}}
+
+	result := reviewPRD(generator, prd)
+	if !result.Success {
+		t.Fatalf("Expected a review, got: %s", result.Message)
+	}
+
+	var sections []string
+	for _, finding := range result.Findings {
+		sections = append(sections, finding.Category+":"+finding.Section)
+	}
+	expected := "missing-section:Acceptance Criteria,missing-section:Non-Functional Requirements,ambiguous:Requirements"
+	if strings.Join(sections, ",") != expected {
+		t.Errorf("Expected findings %s, got %s", expected, strings.Join(sections, ","))
+	}
+	if result.Findings[2].Severity != "medium" {
+		t.Errorf("Expected an unknown severity to become medium, got %s", result.Findings[2].Severity)
+	}
+	if generator.requests[0].Schema != prdReviewSchema {
+		t.Error("Expected the review schema to be requested")
+	}
+}
+
+func TestDraftPRDIsGroundedInRepository(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+	workspacePath := filepath.Join(os.Getenv("HOME"), ".aicodingtool", "repos", "demo")
+	os.MkdirAll(filepath.Join(workspacePath, "internal", "auth"), 0755)
+	os.MkdirAll(filepath.Join(workspacePath, "node_modules", "left-pad"), 0755)
+	os.WriteFile(filepath.Join(workspacePath, "go.mod"), []byte("module example.com/demo\n"), 0644)
+	os.WriteFile(filepath.Join(workspacePath, "internal", "auth", "login.go"), []byte("package auth\n"), 0644)
+	os.WriteFile(filepath.Join(workspacePath, "node_modules", "left-pad", "index.js"), []byte(""), 0644)
+
+	repository, err := describeRepository(workspacePath, prdDraftRepoChars)
+	if err != nil {
+		t.Fatalf("Failed to describe repository: %v", err)
+	}
+	if !strings.Contains(repository, "internal/auth/login.go") || !strings.Contains(repository, "module example.com/demo") {
+		t.Errorf("Expected the file list and go.mod in the summary, got:\n%s", repository)
+	}
+	if strings.Contains(repository, "left-pad") {
+		t.Errorf("Expected node_modules to be skipped, got:\n%s", repository)
+	}
+
+	generator := &scriptedGenerator{responses: []string{"```markdown\n# SSO login\n\n## Overview\nExtend internal/auth.\n```"}}
+	draft := draftPRD(generator, "Log in with SSO", repository)
+	if !draft.Success || draft.Content != "# SSO login\n\n## Overview\nExtend internal/auth.\n" {
+		t.Fatalf("Expected the fenced draft to be unwrapped, got %+v", draft)
+	}
+	if prompt := generator.requests[0].Messages[1].Content; !strings.Contains(prompt, "internal/auth/login.go") || !strings.Contains(prompt, "Log in with SSO") {
+		t.Errorf("Expected the repository and idea in the prompt, got: %s", prompt)
+	}
+
+	if result := app.DraftPRD("demo", "  "); result.Success {
+		t.Error("Expected an empty idea to be rejected")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Kinds of lines in a PRD diff
+const (
+	DiffEqual   = "equal"
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+)
+
+// DiffLine is one line of a line-based diff between two versions of a document
+type DiffLine struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+// prdHash identifies a version of a PRD
+func prdHash(prdContent string) string {
+	sum := sha256.Sum256([]byte(prdContent))
+	return hex.EncodeToString(sum[:])
+}
+
+// splitLines splits text into lines without their line endings
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\n")
+}
+
+// diffLines returns the line diff that turns oldText into newText, based on the longest
+// common subsequence of their lines
+func diffLines(oldText, newText string) []DiffLine {
+	oldLines, newLines := splitLines(oldText), splitLines(newText)
+
+	// common[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	common := make([][]int, len(oldLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			diff = append(diff, DiffLine{Kind: DiffEqual, Text: oldLines[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			diff = append(diff, DiffLine{Kind: DiffRemoved, Text: oldLines[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Kind: DiffAdded, Text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		diff = append(diff, DiffLine{Kind: DiffRemoved, Text: oldLines[i]})
+	}
+	for ; j < len(newLines); j++ {
+		diff = append(diff, DiffLine{Kind: DiffAdded, Text: newLines[j]})
+	}
+
+	return diff
+}
+
+// formatDiff renders a diff in unified style, keeping contextLines unchanged lines around each
+// change and eliding the rest. It returns an empty string if nothing changed.
+func formatDiff(diff []DiffLine, contextLines int) string {
+	// Mark the lines within contextLines of a change
+	keep := make([]bool, len(diff))
+	changed := false
+	for i, line := range diff {
+		if line.Kind == DiffEqual {
+			continue
+		}
+		changed = true
+		for k := max(0, i-contextLines); k <= min(len(diff)-1, i+contextLines); k++ {
+			keep[k] = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	skipped := 0
+	for i, line := range diff {
+		if !keep[i] {
+			skipped++
+			continue
+		}
+		if skipped > 0 {
+			fmt.Fprintf(&out, "@@ %d unchanged lines @@\n", skipped)
+			skipped = 0
+		}
+
+		prefix := " "
+		switch line.Kind {
+		case DiffAdded:
+			prefix = "+"
+		case DiffRemoved:
+			prefix = "-"
+		}
+		out.WriteString(prefix + line.Text + "\n")
+	}
+	if skipped > 0 {
+		fmt.Fprintf(&out, "@@ %d unchanged lines @@\n", skipped)
+	}
+
+	return out.String()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	diff := diffLines("# PRD\nLogin\nSearch\n", "# PRD\nLogin with SSO\nSearch\nExport\n")
+
+	expected := []DiffLine{
+		{Kind: DiffEqual, Text: "# PRD"},
+		{Kind: DiffRemoved, Text: "Login"},
+		{Kind: DiffAdded, Text: "Login with SSO"},
+		{Kind: DiffEqual, Text: "Search"},
+		{Kind: DiffAdded, Text: "Export"},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, diff)
+	}
+}
+
+func TestFormatDiffElidesUnchangedLines(t *testing.T) {
+	diff := diffLines("a\nb\nc\nd\ne\nf\n", "a\nb\nc\nd\ne\nF\n")
+
+	expected := "@@ 4 unchanged lines @@\n e\n-f\n+F\n"
+	if formatted := formatDiff(diff, 1); formatted != expected {
+		t.Errorf("Expected %q, got %q", expected, formatted)
+	}
+	if formatted := formatDiff(diffLines("a\n", "a\n"), 3); formatted != "" {
+		t.Errorf("Expected no diff for identical text, got %q", formatted)
+	}
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Where a PRD version came from
+const (
+	PRDSourceSaved    = "saved"    // Saved through SaveWorkspacePRD
+	PRDSourceRestored = "restored" // Restored from an earlier version
+	PRDSourceExternal = "external" // PRD.md was changed outside the app and snapshotted before it was replaced or used
+)
+
+// PRDVersion describes one snapshot of a workspace's PRD
+type PRDVersion struct {
+	Version      int       `json:"version"`
+	SavedAt      time.Time `json:"savedAt"`
+	Hash         string    `json:"hash"`
+	Size         int       `json:"size"`
+	Source       string    `json:"source"`
+	RestoredFrom int       `json:"restoredFrom,omitempty"` // The version a restored PRD was copied from
+}
+
+// PRDVersionListResult represents the result of listing the PRD versions of a workspace
+type PRDVersionListResult struct {
+	Success  bool         `json:"success"`
+	Message  string       `json:"message"`
+	Versions []PRDVersion `json:"versions,omitempty"` // Most recent first
+}
+
+// PRDVersionResult represents the result of fetching one PRD version
+type PRDVersionResult struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Version *PRDVersion `json:"version,omitempty"`
+	Content string      `json:"content,omitempty"`
+}
+
+// PRDDiffResult represents the result of comparing two PRD versions
+type PRDDiffResult struct {
+	Success bool       `json:"success"`
+	Message string     `json:"message"`
+	From    int        `json:"from"`
+	To      int        `json:"to"`
+	Lines   []DiffLine `json:"lines,omitempty"`
+	Diff    string     `json:"diff,omitempty"` // Unified rendering of Lines with three lines of context
+}
+
+// prdHistoryDir is where PRD snapshots are kept inside a workspace
+const prdHistoryDir = ".specprint/prd-history"
+
+// prdHistoryStore keeps every saved version of a workspace's PRD under .specprint/prd-history
+// in the workspace, next to an index.json that describes them. Named specs have their own
+// history in .specprint/prd-history/specs/<spec>.
+type prdHistoryStore struct {
+	mu sync.Mutex
+}
+
+// dir returns the history directory of a spec document of a workspace
+func (s *prdHistoryStore) dir(workspacePath, specID string) string {
+	dir := filepath.Join(workspacePath, filepath.FromSlash(prdHistoryDir))
+	if specID != DefaultSpecID {
+		dir = filepath.Join(dir, "specs", specID)
+	}
+	return dir
+}
+
+// versionPath returns the path of the snapshot of a version
+func (s *prdHistoryStore) versionPath(workspacePath, specID string, version int) string {
+	return filepath.Join(s.dir(workspacePath, specID), fmt.Sprintf("v%04d.md", version))
+}
+
+// load reads the version index of a spec document; the caller must hold s.mu
+func (s *prdHistoryStore) load(workspacePath, specID string) ([]PRDVersion, error) {
+	var versions []PRDVersion
+	err := readJSONFile(filepath.Join(s.dir(workspacePath, specID), "index.json"), &versions)
+	return versions, err
+}
+
+// List returns the versions of a spec document, oldest first
+func (s *prdHistoryStore) List(workspacePath, specID string) ([]PRDVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load(workspacePath, specID)
+}
+
+// Get returns a version and its content
+func (s *prdHistoryStore) Get(workspacePath, specID string, version int) (PRDVersion, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(workspacePath, specID)
+	if err != nil {
+		return PRDVersion{}, "", err
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			content, err := os.ReadFile(s.versionPath(workspacePath, specID, version))
+			if err != nil {
+				return PRDVersion{}, "", err
+			}
+			return v, string(content), nil
+		}
+	}
+	return PRDVersion{}, "", fmt.Errorf("PRD version %d not found", version)
+}
+
+// Record snapshots PRD content as a new version. Content identical to the latest version is not
+// recorded again; the latest version is returned instead.
+func (s *prdHistoryStore) Record(workspacePath, specID, content, source string, restoredFrom int) (PRDVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(workspacePath, specID)
+	if err != nil {
+		return PRDVersion{}, err
+	}
+
+	hash := prdHash(content)
+	if len(versions) > 0 && versions[len(versions)-1].Hash == hash {
+		return versions[len(versions)-1], nil
+	}
+
+	version := PRDVersion{
+		Version:      len(versions) + 1,
+		SavedAt:      time.Now(),
+		Hash:         hash,
+		Size:         len(content),
+		Source:       source,
+		RestoredFrom: restoredFrom,
+	}
+
+	if err := os.MkdirAll(s.dir(workspacePath, specID), 0755); err != nil {
+		return PRDVersion{}, err
+	}
+	excludeFromGit(workspacePath, ".specprint/")
+	if err := os.WriteFile(s.versionPath(workspacePath, specID, version.Version), []byte(content), 0644); err != nil {
+		return PRDVersion{}, err
+	}
+
+	versions = append(versions, version)
+	if err := writeJSONFile(filepath.Join(s.dir(workspacePath, specID), "index.json"), versions); err != nil {
+		return PRDVersion{}, err
+	}
+	return version, nil
+}
+
+// FindByHash returns the latest version with the given content hash, or 0 if there is none
+func (s *prdHistoryStore) FindByHash(workspacePath, specID, hash string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(workspacePath, specID)
+	if err != nil {
+		return 0, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Hash == hash {
+			return versions[i].Version, nil
+		}
+	}
+	return 0, nil
+}
+
+// excludeFromGit adds a pattern to the repository's .git/info/exclude so app data kept in the
+// workspace does not show up as untracked files. Failures are ignored; the data is still usable.
+func excludeFromGit(repoPath, pattern string) {
+	excludeFile := filepath.Join(repoPath, ".git", "info", "exclude")
+	if _, err := os.Stat(filepath.Join(repoPath, ".git")); err != nil {
+		return
+	}
+
+	existing, err := os.ReadFile(excludeFile)
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(excludeFile), 0755); err != nil {
+		return
+	}
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		pattern = "\n" + pattern
+	}
+	file, err := os.OpenFile(excludeFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	file.WriteString(pattern + "\n")
+}
+
+// recordCurrentSpec snapshots a spec document as it is on disk, so edits made outside the app
+// are kept before it is replaced or used. It returns the version of the file, or 0 if the
+// workspace does not have the spec.
+func (a *App) recordCurrentSpec(workspace *Workspace, specID string) (int, string, error) {
+	content, err := os.ReadFile(specPath(workspace.Path, specID))
+	if os.IsNotExist(err) {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	version, err := a.prdHistory.FindByHash(workspace.Path, specID, prdHash(string(content)))
+	if err != nil {
+		return 0, "", err
+	}
+	if version > 0 {
+		return version, string(content), nil
+	}
+
+	recorded, err := a.prdHistory.Record(workspace.Path, specID, string(content), PRDSourceExternal, 0)
+	if err != nil {
+		return 0, "", err
+	}
+	return recorded.Version, string(content), nil
+}
+
+// ListPRDVersions returns the saved versions of a spec document of a workspace, most recent
+// first. An empty spec ID stands for PRD.md.
+func (a *App) ListPRDVersions(workspaceName, specID string) PRDVersionListResult {
+	targetWorkspace, err := a.findSpecWorkspace(workspaceName, specID)
+	if err != nil {
+		return PRDVersionListResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	if _, _, err := a.recordCurrentSpec(targetWorkspace, specID); err != nil {
+		return PRDVersionListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to record the current PRD: %v", err),
+		}
+	}
+
+	versions, err := a.prdHistory.List(targetWorkspace.Path, specID)
+	if err != nil {
+		return PRDVersionListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load PRD history: %v", err),
+		}
+	}
+
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+
+	return PRDVersionListResult{
+		Success:  true,
+		Message:  fmt.Sprintf("Found %d versions of %s in workspace '%s'", len(versions), specLabel(specID), workspaceName),
+		Versions: versions,
+	}
+}
+
+// GetPRDVersion returns the content of one version of a spec document of a workspace
+func (a *App) GetPRDVersion(workspaceName, specID string, version int) PRDVersionResult {
+	targetWorkspace, err := a.findSpecWorkspace(workspaceName, specID)
+	if err != nil {
+		return PRDVersionResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	prdVersion, content, err := a.prdHistory.Get(targetWorkspace.Path, specID, version)
+	if err != nil {
+		return PRDVersionResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load PRD version %d: %v", version, err),
+		}
+	}
+
+	return PRDVersionResult{
+		Success: true,
+		Message: fmt.Sprintf("Loaded PRD version %d", version),
+		Version: &prdVersion,
+		Content: content,
+	}
+}
+
+// DiffPRDVersions compares two versions of a spec document of a workspace. A version of 0
+// stands for the current file.
+func (a *App) DiffPRDVersions(workspaceName, specID string, from, to int) PRDDiffResult {
+	targetWorkspace, err := a.findSpecWorkspace(workspaceName, specID)
+	if err != nil {
+		return PRDDiffResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	contents := make([]string, 2)
+	for i, version := range []int{from, to} {
+		if version == 0 {
+			_, content, err := a.recordCurrentSpec(targetWorkspace, specID)
+			if err != nil {
+				return PRDDiffResult{
+					Success: false,
+					Message: fmt.Sprintf("Failed to read PRD file: %v", err),
+				}
+			}
+			contents[i] = content
+			continue
+		}
+
+		_, content, err := a.prdHistory.Get(targetWorkspace.Path, specID, version)
+		if err != nil {
+			return PRDDiffResult{
+				Success: false,
+				Message: fmt.Sprintf("Failed to load PRD version %d: %v", version, err),
+			}
+		}
+		contents[i] = content
+	}
+
+	lines := diffLines(contents[0], contents[1])
+	return PRDDiffResult{
+		Success: true,
+		Message: fmt.Sprintf("Compared PRD version %d with version %d", from, to),
+		From:    from,
+		To:      to,
+		Lines:   lines,
+		Diff:    formatDiff(lines, 3),
+	}
+}
+
+// RestorePRDVersion replaces a spec document of a workspace with an earlier version, which is
+// recorded as a new version so the history stays linear
+func (a *App) RestorePRDVersion(workspaceName, specID string, version int) PRDResult {
+	targetWorkspace, err := a.findSpecWorkspace(workspaceName, specID)
+	if err != nil {
+		return PRDResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	_, content, err := a.prdHistory.Get(targetWorkspace.Path, specID, version)
+	if err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load PRD version %d: %v", version, err),
+		}
+	}
+
+	if _, _, err := a.recordCurrentSpec(targetWorkspace, specID); err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to record the current PRD: %v", err),
+		}
+	}
+
+	prdFilePath := specPath(targetWorkspace.Path, specID)
+	if err := os.MkdirAll(filepath.Dir(prdFilePath), 0755); err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to write PRD file: %v", err),
+		}
+	}
+	if err := os.WriteFile(prdFilePath, []byte(content), 0644); err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to write PRD file: %v", err),
+		}
+	}
+
+	restored, err := a.prdHistory.Record(targetWorkspace.Path, specID, content, PRDSourceRestored, version)
+	if err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("Restored PRD version %d but failed to record it: %v", version, err),
+			Path:    prdFilePath,
+		}
+	}
+
+	return PRDResult{
+		Success: true,
+		Message: fmt.Sprintf("Restored PRD version %d as version %d", version, restored.Version),
+		Path:    prdFilePath,
+		Version: restored.Version,
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPRDHistorySnapshotsDiffsAndRestores(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+	workspacePath := filepath.Join(os.Getenv("HOME"), ".aicodingtool", "repos", "demo")
+	os.MkdirAll(filepath.Join(workspacePath, ".git", "info"), 0755)
+
+	first := app.SaveWorkspacePRD("demo", "Users can log in.")
+	if !first.Success || first.Version != 1 {
+		t.Fatalf("Expected version 1, got %+v", first)
+	}
+
+	// Edits made outside the app are kept before the next save replaces them
+	os.WriteFile(filepath.Join(workspacePath, "PRD.md"), []byte("Users can log in.\nHand-written note.\n"), 0644)
+	second := app.SaveWorkspacePRD("demo", "Users can log in with SSO.")
+	if !second.Success || second.Version != 3 {
+		t.Fatalf("Expected the external edit as version 2 and the save as version 3, got %+v", second)
+	}
+
+	list := app.ListPRDVersions("demo", "")
+	if !list.Success || len(list.Versions) != 3 {
+		t.Fatalf("Expected 3 versions, got %+v", list)
+	}
+	if list.Versions[0].Version != 3 || list.Versions[1].Source != PRDSourceExternal {
+		t.Errorf("Expected most recent first with the external edit recorded, got %+v", list.Versions)
+	}
+
+	diff := app.DiffPRDVersions("demo", "", 1, 0)
+	if !diff.Success || !strings.Contains(diff.Diff, "-Users can log in.") || !strings.Contains(diff.Diff, "+Users can log in with SSO.") {
+		t.Errorf("Expected a diff from version 1 to the current PRD, got %+v", diff)
+	}
+
+	restored := app.RestorePRDVersion("demo", "", 1)
+	if !restored.Success || restored.Version != 4 {
+		t.Fatalf("Expected the restore to be recorded as version 4, got %+v", restored)
+	}
+	original := app.GetPRDVersion("demo", "", 1)
+	current, _ := os.ReadFile(filepath.Join(workspacePath, "PRD.md"))
+	if !original.Success || string(current) != original.Content {
+		t.Errorf("Expected PRD.md to match version 1, got %q", current)
+	}
+
+	exclude, _ := os.ReadFile(filepath.Join(workspacePath, ".git", "info", "exclude"))
+	if strings.Count(string(exclude), ".specprint/") != 1 {
+		t.Errorf("Expected the history to be excluded from git once, got %q", exclude)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"specprint/pkg/forge"
+)
+
+// forgeRequestTimeout bounds a single request to the forge API
+const forgeRequestTimeout = 30 * time.Second
+
+// PullRequestResult represents the result of opening or looking up the pull request of a task
+type PullRequestResult struct {
+	Success     bool               `json:"success"`
+	Message     string             `json:"message"`
+	PullRequest *forge.PullRequest `json:"pullRequest,omitempty"`
+}
+
+// workspaceForge creates the forge client for the origin remote of a workspace
+func (a *App) workspaceForge(targetWorkspace *Workspace) (forge.Client, error) {
+	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = targetWorkspace.Path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("workspace '%s' has no origin remote", targetWorkspace.Name)
+	}
+
+	repo, err := forge.ParseRemoteURL(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := a.forgeConfig(targetWorkspace.Name)
+	if err != nil {
+		return nil, err
+	}
+	return forge.NewClient(config, repo)
+}
+
+// pullRequestBody describes a task's changes for reviewers: the task, its acceptance criteria
+// and test plan, what Claude reported doing and how the changes were verified
+func pullRequestBody(task Task, summary string) string {
+	var body strings.Builder
+	if task.Description != "" {
+		body.WriteString(task.Description + "\n")
+	}
+
+	if len(task.AcceptanceCriteria) > 0 {
+		body.WriteString("\n## Acceptance Criteria\n")
+		for _, criterion := range task.AcceptanceCriteria {
+			fmt.Fprintf(&body, "- [ ] %s\n", criterion)
+		}
+	}
+	if task.TestPlan != "" {
+		fmt.Fprintf(&body, "\n## Test Plan\n%s\n", task.TestPlan)
+	}
+	if summary != "" {
+		fmt.Fprintf(&body, "\n## Summary\n%s\n", strings.TrimSpace(summary))
+	}
+	if task.LastVerification != nil {
+		body.WriteString("\n## Verification\n")
+		for _, step := range task.LastVerification.Steps {
+			outcome := "passed"
+			if !step.Passed {
+				outcome = "failed"
+			}
+			fmt.Fprintf(&body, "- %s: `%s` %s\n", step.Name, step.Command, outcome)
+		}
+	}
+
+	fmt.Fprintf(&body, "\n---\nImplements task #%d.\n", task.ID)
+	return body.String()
+}
+
+// openTaskPullRequest opens a pull request from a task's branch into baseBranch and records it
+// on the task
+func (a *App) openTaskPullRequest(targetWorkspace *Workspace, task Task, baseBranch, summary string) (*forge.PullRequest, error) {
+	client, err := a.workspaceForge(targetWorkspace)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forgeRequestTimeout)
+	defer cancel()
+	pr, err := client.CreatePullRequest(ctx, forge.NewPullRequest{
+		Title: task.Title,
+		Body:  pullRequestBody(task, summary),
+		Head:  task.BranchName,
+		Base:  baseBranch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a pull request on %s: %v", client.Name(), err)
+	}
+
+	_, err = a.tasks.Update(targetWorkspace.Name, func(board *TaskBoard) error {
+		index := board.findTask(task.ID)
+		if index < 0 {
+			return fmt.Errorf("task %d not found", task.ID)
+		}
+		board.Tasks[index].PullRequest = &pr
+		return nil
+	})
+	if err != nil {
+		return &pr, fmt.Errorf("opened pull request #%d but failed to record it on the task: %v", pr.Number, err)
+	}
+	return &pr, nil
+}
+
+// CreateTaskPullRequest opens a pull request from the pushed branch of a task into baseBranch.
+// Tasks that already have an open pull request return it instead.
+func (a *App) CreateTaskPullRequest(workspaceName string, taskID int, baseBranch string) PullRequestResult {
+	if strings.TrimSpace(baseBranch) == "" {
+		return PullRequestResult{
+			Success: false,
+			Message: "Base branch cannot be empty",
+		}
+	}
+
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
+		return PullRequestResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return PullRequestResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load tasks: %v", err),
+		}
+	}
+	index := board.findTask(taskID)
+	if index < 0 {
+		return PullRequestResult{
+			Success: false,
+			Message: fmt.Sprintf("Task %d not found", taskID),
+		}
+	}
+	task := board.Tasks[index]
+
+	if task.PullRequest != nil && task.PullRequest.State == forge.StateOpen {
+		return PullRequestResult{
+			Success:     true,
+			Message:     fmt.Sprintf("Task %d already has pull request #%d", taskID, task.PullRequest.Number),
+			PullRequest: task.PullRequest,
+		}
+	}
+	if task.BranchName == "" {
+		return PullRequestResult{
+			Success: false,
+			Message: fmt.Sprintf("Task %d has no pushed branch yet; run it first", taskID),
+		}
+	}
+
+	pr, err := a.openTaskPullRequest(targetWorkspace, task, baseBranch, task.LastSummary)
+	if err != nil {
+		return PullRequestResult{
+			Success:     false,
+			Message:     err.Error(),
+			PullRequest: pr,
+		}
+	}
+
+	return PullRequestResult{
+		Success:     true,
+		Message:     fmt.Sprintf("Opened pull request #%d for task %d: %s", pr.Number, taskID, pr.URL),
+		PullRequest: pr,
+	}
+}
+
+// autoOpenPullRequest opens a pull request after a run pushed a task's branch, if the workspace
+// asks for it. Failing to open it does not fail the run; the reason is added to the message.
+func (a *App) autoOpenPullRequest(targetWorkspace *Workspace, taskID int, baseBranch string, result *TaskExecutionResult) {
+	settings, err := a.settings.LoadWorkspace(targetWorkspace.Name)
+	if err != nil || !settings.AutoPullRequest {
+		return
+	}
+
+	board, err := a.tasks.Load(targetWorkspace.Name)
+	if err != nil {
+		return
+	}
+	index := board.findTask(taskID)
+	if index < 0 {
+		return
+	}
+	task := board.Tasks[index]
+
+	// Pushing to the branch already updated an open pull request
+	if task.PullRequest != nil && task.PullRequest.State == forge.StateOpen {
+		result.PullRequest = task.PullRequest
+		return
+	}
+
+	pr, err := a.openTaskPullRequest(targetWorkspace, task, baseBranch, result.ClaudeSummary)
+	result.PullRequest = pr
+	if err != nil {
+		result.Message += fmt.Sprintf("; %v", err)
+		return
+	}
+	result.Message += fmt.Sprintf("; opened pull request #%d", pr.Number)
+}
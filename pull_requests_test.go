@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"specprint/pkg/forge"
+)
+
+// setupForgeWorkspace creates a workspace whose origin is a GitHub repository served by handler
+func setupForgeWorkspace(t *testing.T, handler http.HandlerFunc) *App {
+	t.Helper()
+
+	app := setupTestWorkspace(t, "demo")
+	workspacePath := filepath.Join(os.Getenv("HOME"), ".aicodingtool", "repos", "demo")
+	for _, args := range [][]string{{"init", "-q"}, {"remote", "add", "origin", "https://github.com/acme/shop.git"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = workspacePath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("GITHUB_TOKEN", "secret")
+	if result := app.SaveWorkspaceSettings("demo", WorkspaceSettings{Forge: forge.Config{BaseURL: server.URL}}); !result.Success {
+		t.Fatalf("Failed to save settings: %s", result.Message)
+	}
+	return app
+}
+
+func TestCreateTaskPullRequest(t *testing.T) {
+	requests := 0
+	app := setupForgeWorkspace(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var received map[string]string
+		json.NewDecoder(r.Body).Decode(&received)
+		if r.URL.Path != "/repos/acme/shop/pulls" || received["head"] != "task-1-add-login" || received["base"] != "main" || received["title"] != "Add login" {
+			t.Errorf("Unexpected request to %s: %v", r.URL.Path, received)
+		}
+		if !strings.Contains(received["body"], "- [ ] Valid credentials return a token") || !strings.Contains(received["body"], "Added the login handler") {
+			t.Errorf("Expected criteria and summary in the body, got: %s", received["body"])
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number":12,"html_url":"https://github.com/acme/shop/pull/12","state":"open"}`))
+	})
+
+	app.SaveTasks("demo", []Task{{
+		ID:                 1,
+		Title:              "Add login",
+		Description:        "Login endpoint",
+		AcceptanceCriteria: []string{"Valid credentials return a token"},
+		LastSummary:        "Added the login handler",
+	}})
+	if result := app.CreateTaskPullRequest("demo", 1, "main"); result.Success {
+		t.Fatal("Expected a task without branch to be rejected")
+	}
+
+	app.tasks.Update("demo", func(board *TaskBoard) error {
+		board.Tasks[0].BranchName = "task-1-add-login"
+		return nil
+	})
+	result := app.CreateTaskPullRequest("demo", 1, "main")
+	if !result.Success || result.PullRequest.Number != 12 {
+		t.Fatalf("Expected pull request #12, got %+v", result)
+	}
+	if board, _ := app.tasks.Load("demo"); board.Tasks[0].PullRequest == nil || board.Tasks[0].PullRequest.URL != "https://github.com/acme/shop/pull/12" {
+		t.Errorf("Expected the pull request to be recorded on the task, got %+v", board.Tasks[0].PullRequest)
+	}
+
+	again := app.CreateTaskPullRequest("demo", 1, "main")
+	if !again.Success || again.PullRequest.Number != 12 || requests != 1 {
+		t.Errorf("Expected the existing pull request to be returned without a new request, got %+v after %d requests", again, requests)
+	}
+}
+
+func TestPullRequestBodyIncludesVerification(t *testing.T) {
+	body := pullRequestBody(Task{
+		ID:               4,
+		Description:      "Add search",
+		TestPlan:         "Run the search tests",
+		LastVerification: &VerificationResult{Passed: true, Steps: []VerificationStepResult{{Name: "test", Command: "go test ./...", Passed: true}}},
+	}, "")
+
+	for _, expected := range []string{"Add search\n", "## Test Plan\nRun the search tests", "- test: `go test ./...` passed", "Implements task #4."} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected %q in the body, got:\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, "## Summary") {
+		t.Errorf("Expected no summary section without a summary, got:\n%s", body)
+	}
+}
+
+func TestMarkedRunOpensPullRequest(t *testing.T) {
+	var body string
+	app := setupForgeWorkspace(t, func(w http.ResponseWriter, r *http.Request) {
+		var received map[string]string
+		json.NewDecoder(r.Body).Decode(&received)
+		body = received["body"]
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number":7,"html_url":"https://github.com/acme/shop/pull/7","state":"open"}`))
+	})
+	settings, _ := app.settings.LoadWorkspace("demo")
+	settings.AutoPullRequest = true
+	if result := app.SaveWorkspaceSettings("demo", settings); !result.Success {
+		t.Fatalf("Failed to save settings: %s", result.Message)
+	}
+	if err := app.startTaskRun("demo", 1, "Add login", "Login endpoint", "", ""); err != nil {
+		t.Fatalf("Expected task to start, got: %v", err)
+	}
+
+	// A run whose verification failed, but whose changes were pushed with onFailure "mark"
+	workspace, _ := app.findWorkspace("demo")
+	result := app.completeTaskRun(workspace, 1, "main", TaskExecutionResult{
+		Success:      false,
+		Message:      "Task 1 was committed and pushed to branch 'task-1-add-login', but verification step 'test' failed",
+		BranchName:   "task-1-add-login",
+		FilesChanged: []string{"login.go"},
+		Verification: &VerificationResult{Passed: false, Steps: []VerificationStepResult{{Name: "test", Command: "go test ./...", Passed: false}}},
+		Pushed:       true,
+	})
+	if result.Success || result.PullRequest == nil || result.PullRequest.Number != 7 {
+		t.Fatalf("Expected a failed run with pull request #7, got %+v", result)
+	}
+	if !strings.Contains(body, "- test: `go test ./...` failed") {
+		t.Errorf("Expected the failed verification in the body, got:\n%s", body)
+	}
+
+	task := app.ListTasks("demo").Tasks[0]
+	if task.Status != TaskStatusFailed || task.BranchName != "task-1-add-login" || task.PullRequest == nil {
+		t.Errorf("Expected a failed task with its branch and pull request, got %+v", task)
+	}
+}
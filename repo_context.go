@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxListedRepoFiles bounds how many file paths of a repository are shown to the model
+const maxListedRepoFiles = 400
+
+// maxListedRepoDirs bounds how many directories of a repository are shown to the model
+const maxListedRepoDirs = 80
+
+// maxRepoDirDepth is how many levels of directories are shown to the model
+const maxRepoDirDepth = 2
+
+// maxKeyFileChars bounds how much of each key file is shown to the model
+const maxKeyFileChars = 3000
+
+// taskGenerationRepoChars bounds the repository summary included in task generation prompts
+const taskGenerationRepoChars = 12000
+
+// skippedRepoDirs are directories that never describe the code of a repository
+var skippedRepoDirs = map[string]bool{
+	".git":         true,
+	".specprint":   true,
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+	"__pycache__":  true,
+}
+
+// repoLanguages maps file extensions to the language reported for them
+var repoLanguages = map[string]string{
+	".go":     "Go",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".js":     "JavaScript",
+	".jsx":    "JavaScript",
+	".py":     "Python",
+	".rs":     "Rust",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".rb":     "Ruby",
+	".php":    "PHP",
+	".cs":     "C#",
+	".c":      "C",
+	".h":      "C",
+	".cpp":    "C++",
+	".swift":  "Swift",
+	".scala":  "Scala",
+	".sql":    "SQL",
+	".sh":     "Shell",
+	".css":    "CSS",
+	".scss":   "CSS",
+	".html":   "HTML",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+}
+
+// repoKeyFiles are files that describe a repository's purpose, stack and dependencies, in the
+// order they are shown to the model
+var repoKeyFiles = []string{
+	"README.md",
+	"README",
+	"go.mod",
+	"package.json",
+	"Cargo.toml",
+	"pyproject.toml",
+	"requirements.txt",
+	"pom.xml",
+	"build.gradle",
+	"Gemfile",
+	"composer.json",
+}
+
+// listRepositoryFiles returns the paths of the files in a repository, relative to its root and
+// sorted. Tracked files are listed through git so ignored files are left out; directories that
+// are not git repositories are walked instead.
+func listRepositoryFiles(repoPath string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files")
+	cmd.Dir = repoPath
+	if output, err := cmd.Output(); err == nil {
+		var files []string
+		for _, line := range strings.Split(string(output), "\n") {
+			if line != "" && !inSkippedRepoDir(line) {
+				files = append(files, line)
+			}
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+
+	var files []string
+	err := filepath.WalkDir(repoPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != repoPath && skippedRepoDirs[entry.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relative, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relative))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// inSkippedRepoDir checks if a slash-separated relative path lies in a skipped directory
+func inSkippedRepoDir(path string) bool {
+	for _, part := range strings.Split(path, "/")[:strings.Count(path, "/")] {
+		if skippedRepoDirs[part] {
+			return true
+		}
+	}
+	return false
+}
+
+// describeRepository summarizes a repository for a prompt: its languages, directories and file
+// list followed by the start of its key files. The summary is cut to at most maxChars characters.
+func describeRepository(repoPath string, maxChars int) (string, error) {
+	files, err := listRepositoryFiles(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to list repository files: %v", err)
+	}
+
+	var summary strings.Builder
+	if languages := repositoryLanguages(files); languages != "" {
+		fmt.Fprintf(&summary, "Languages (files): %s\n\n", languages)
+	}
+
+	if directories := repositoryDirectories(files); len(directories) > 0 {
+		summary.WriteString("Directories (files):\n")
+		for _, directory := range directories {
+			summary.WriteString(directory + "\n")
+		}
+		summary.WriteString("\n")
+	}
+
+	fmt.Fprintf(&summary, "Files (%d):\n", len(files))
+	for i, file := range files {
+		if i == maxListedRepoFiles {
+			fmt.Fprintf(&summary, "... and %d more files\n", len(files)-maxListedRepoFiles)
+			break
+		}
+		summary.WriteString(file + "\n")
+	}
+
+	for _, name := range repoKeyFiles {
+		content, err := os.ReadFile(filepath.Join(repoPath, name))
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&summary, "\n--- %s ---\n%s\n", name, truncateText(string(content), maxKeyFileChars))
+	}
+
+	return truncateText(summary.String(), maxChars), nil
+}
+
+// repositoryLanguages lists the languages of a repository with their number of files, most files
+// first, e.g. "Go 42, TypeScript 30"
+func repositoryLanguages(files []string) string {
+	counts := make(map[string]int)
+	for _, file := range files {
+		if language, ok := repoLanguages[strings.ToLower(filepath.Ext(file))]; ok {
+			counts[language]++
+		}
+	}
+
+	languages := make([]string, 0, len(counts))
+	for language := range counts {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if counts[languages[i]] != counts[languages[j]] {
+			return counts[languages[i]] > counts[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+
+	parts := make([]string, 0, len(languages))
+	for _, language := range languages {
+		parts = append(parts, fmt.Sprintf("%s %d", language, counts[language]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// repositoryDirectories lists the directories of a repository up to maxRepoDirDepth levels deep
+// with the number of files below each, e.g. "pkg/claude/ 4"
+func repositoryDirectories(files []string) []string {
+	counts := make(map[string]int)
+	for _, file := range files {
+		parts := strings.Split(file, "/")
+		for depth := 1; depth < len(parts) && depth <= maxRepoDirDepth; depth++ {
+			counts[strings.Join(parts[:depth], "/")+"/"]++
+		}
+	}
+
+	directories := make([]string, 0, len(counts))
+	for directory := range counts {
+		directories = append(directories, directory)
+	}
+	sort.Strings(directories)
+
+	var lines []string
+	for i, directory := range directories {
+		if i == maxListedRepoDirs {
+			lines = append(lines, fmt.Sprintf("... and %d more directories", len(directories)-maxListedRepoDirs))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s %d", directory, counts[directory]))
+	}
+	return lines
+}
+
+// workspaceRepositorySummary describes the repository of a workspace for task generation. The
+// summary is best effort: it is empty if the workspace cannot be read.
+func (a *App) workspaceRepositorySummary(workspaceName string) string {
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
+		return ""
+	}
+	summary, err := describeRepository(targetWorkspace.Path, taskGenerationRepoChars)
+	if err != nil {
+		return ""
+	}
+	return summary
+}
+
+// repositoryPrompt introduces a repository summary in a task generation prompt. It returns an
+// empty string if there is no summary.
+func repositoryPrompt(repository string) string {
+	if repository == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\nThe PRD will be implemented in this existing repository:\n%s\n\nReference its real files and modules in task descriptions, and do not create tasks for work the repository already has, such as setting up the project, the build or a database schema that already exist.", repository)
+}
+
+// truncateText cuts text to at most maxChars bytes without splitting a character, marking the cut
+func truncateText(text string, maxChars int) string {
+	if len(text) <= maxChars {
+		return text
+	}
+	return strings.ToValidUTF8(text[:maxChars], "") + "\n[truncated]"
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDescribeRepositorySummarizesLanguagesAndDirectories(t *testing.T) {
+	repoPath := t.TempDir()
+	files := map[string]string{
+		"go.mod":                   "module example.com/shop\n",
+		"main.go":                  "package main\n",
+		"internal/db/schema.go":    "package db\n",
+		"internal/db/migrate.go":   "package db\n",
+		"web/src/app.tsx":          "export {}\n",
+		".git/HEAD":                "ref: refs/heads/main\n",
+		".specprint/prd-history/x": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(repoPath, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	summary, err := describeRepository(repoPath, taskGenerationRepoChars)
+	if err != nil {
+		t.Fatalf("Failed to describe repository: %v", err)
+	}
+	for _, expected := range []string{
+		"Languages (files): Go 3, TypeScript 1",
+		"internal/ 2\ninternal/db/ 2\n",
+		"Files (5):",
+		"--- go.mod ---\nmodule example.com/shop",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("Expected %q in the summary, got:\n%s", expected, summary)
+		}
+	}
+	if strings.Contains(summary, "HEAD") || strings.Contains(summary, "prd-history") {
+		t.Errorf("Expected .git and .specprint to be skipped, got:\n%s", summary)
+	}
+
+	if truncated, _ := describeRepository(repoPath, 20); !strings.HasSuffix(truncated, "[truncated]") {
+		t.Errorf("Expected the summary to be cut, got %q", truncated)
+	}
+}
+
+func TestGenerateTasksIncludesRepositorySummary(t *testing.T) {
+	generator := &scriptedGenerator{responses: []string{
+		`{"tasks":[{"id":1,"title":"Add orders table","description":"Extend internal/db/schema.go","dependencies":[],"priority":"high","estimate":"2h"}]}`,
+	}}
+
+	result := generateTasks(generator, "Customers can order", TaskGenerationOptions{Repository: "Files (1):\ninternal/db/schema.go\n"})
+	if !result.Success {
+		t.Fatalf("Expected tasks, got: %s", result.Message)
+	}
+	prompt := generator.requests[0].Messages[1].Content
+	if !strings.Contains(prompt, "internal/db/schema.go") || !strings.Contains(prompt, "do not create tasks for work the repository already has") {
+		t.Errorf("Expected the repository summary in the prompt, got: %s", prompt)
+	}
+
+	generator = &scriptedGenerator{responses: []string{`{"tasks":[]}`}}
+	generateTasks(generator, "Customers can order", TaskGenerationOptions{})
+	if prompt := generator.requests[0].Messages[1].Content; strings.Contains(prompt, "existing repository") {
+		t.Errorf("Expected no repository section without a summary, got: %s", prompt)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"specprint/pkg/forge"
+)
+
+// reviewFeedbackPrompt composes the follow-up message that asks Claude to address the
+// unresolved review threads of a pull request
+func reviewFeedbackPrompt(pr forge.PullRequest, threads []forge.ReviewThread) string {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Reviewers left feedback on pull request #%d for this task. ", pr.Number)
+	prompt.WriteString("Address every unresolved comment below by changing the code; where you disagree with a comment, leave the code as it is and explain why in your summary. Keep the changes focused on the feedback.\n")
+
+	for _, thread := range threads {
+		if thread.Path == "" {
+			prompt.WriteString("\n## Feedback on the whole pull request\n")
+		} else if thread.Line > 0 {
+			fmt.Fprintf(&prompt, "\n## %s:%d\n", thread.Path, thread.Line)
+		} else {
+			fmt.Fprintf(&prompt, "\n## %s\n", thread.Path)
+		}
+		if thread.DiffHunk != "" {
+			fmt.Fprintf(&prompt, "```diff\n%s\n```\n", strings.TrimRight(thread.DiffHunk, "\n"))
+		}
+		for _, comment := range thread.Comments {
+			fmt.Fprintf(&prompt, "%s wrote:\n%s\n", comment.Author, strings.TrimSpace(comment.Body))
+		}
+	}
+	return prompt.String()
+}
+
+// AddressReviewFeedback continues the Claude session of a task with the unresolved review
+// feedback on its pull request, then commits and pushes the fixes to the pull request's branch
+func (a *App) AddressReviewFeedback(workspaceName string, taskID int) ClaudeSessionResult {
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
+		return ClaudeSessionResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return ClaudeSessionResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load tasks: %v", err),
+		}
+	}
+	index := board.findTask(taskID)
+	if index < 0 {
+		return ClaudeSessionResult{
+			Success: false,
+			Message: fmt.Sprintf("Task %d not found", taskID),
+		}
+	}
+	task := board.Tasks[index]
+
+	if task.PullRequest == nil || task.PullRequest.State != forge.StateOpen {
+		return ClaudeSessionResult{
+			Success: false,
+			Message: fmt.Sprintf("Task %d has no open pull request", taskID),
+		}
+	}
+	if task.SessionID == "" || task.WorktreePath == "" {
+		return ClaudeSessionResult{
+			Success: false,
+			Message: fmt.Sprintf("Task %d has no Claude session to continue", taskID),
+		}
+	}
+	if _, err := os.Stat(task.WorktreePath); os.IsNotExist(err) {
+		return ClaudeSessionResult{
+			Success: false,
+			Message: fmt.Sprintf("Worktree of task %d was removed: %s", taskID, task.WorktreePath),
+		}
+	}
+
+	client, err := a.workspaceForge(targetWorkspace)
+	if err != nil {
+		return ClaudeSessionResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), forgeRequestTimeout)
+	threads, err := client.ReviewThreads(ctx, task.PullRequest.Number)
+	cancel()
+	if err != nil {
+		return ClaudeSessionResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load the review comments of pull request #%d from %s: %v", task.PullRequest.Number, client.Name(), err),
+		}
+	}
+	if len(threads) == 0 {
+		return ClaudeSessionResult{
+			Success: true,
+			Message: fmt.Sprintf("Pull request #%d has no unresolved review comments", task.PullRequest.Number),
+		}
+	}
+
+	request := fmt.Sprintf("Address %d review comment(s) on pull request #%d", len(threads), task.PullRequest.Number)
+	return a.continueSession(task.SessionID, reviewFeedbackPrompt(*task.PullRequest, threads), request, task.WorktreePath)
+}
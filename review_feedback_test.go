@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"specprint/pkg/forge"
+)
+
+func TestReviewFeedbackPrompt(t *testing.T) {
+	prompt := reviewFeedbackPrompt(forge.PullRequest{Number: 12}, []forge.ReviewThread{
+		{Comments: []forge.ReviewComment{{Author: "carol", Body: "Needs tests\n"}}},
+		{Path: "login.go", Line: 8, DiffHunk: "@@ -5,3 +5,4 @@\n+\treturn nil\n", Comments: []forge.ReviewComment{
+			{Author: "bob", Body: "Check the error"},
+			{Author: "amy", Body: "Agreed"},
+		}},
+	})
+
+	for _, expected := range []string{
+		"pull request #12",
+		"## Feedback on the whole pull request\ncarol wrote:\nNeeds tests\n",
+		"## login.go:8\n```diff\n@@ -5,3 +5,4 @@\n+\treturn nil\n```\nbob wrote:\nCheck the error\namy wrote:\nAgreed\n",
+	} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected %q in the prompt, got:\n%s", expected, prompt)
+		}
+	}
+}
+
+func TestAddressReviewFeedback(t *testing.T) {
+	app := setupForgeWorkspace(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"nodes":[
+			{"isResolved":true,"path":"login.go","line":3,"comments":{"nodes":[{"author":{"login":"bob"},"body":"Fixed"}]}}]},
+			"reviews":{"nodes":[]}}}}}`))
+	})
+
+	worktree := t.TempDir()
+	app.SaveTasks("demo", []Task{
+		{ID: 1, Title: "No pull request", SessionID: "session-1", WorktreePath: worktree},
+		{ID: 2, Title: "No session", PullRequest: &forge.PullRequest{Number: 2, State: forge.StateOpen}},
+		{ID: 3, Title: "Resolved", SessionID: "session-3", WorktreePath: worktree, PullRequest: &forge.PullRequest{Number: 3, State: forge.StateOpen}},
+	})
+
+	for _, taskID := range []int{1, 2, 4} {
+		if result := app.AddressReviewFeedback("demo", taskID); result.Success {
+			t.Errorf("Expected task %d to be rejected, got %+v", taskID, result)
+		}
+	}
+
+	// Only resolved threads: there is nothing to send to Claude
+	result := app.AddressReviewFeedback("demo", 3)
+	if !result.Success || result.Message != "Pull request #3 has no unresolved review comments" {
+		t.Errorf("Expected no feedback to address, got %+v", result)
+	}
+}
+
+func TestRunWithoutChangesKeepsTaskWithOpenPullRequestInReview(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	app.SaveTasks("demo", []Task{
+		{ID: 1, Title: "Add login", Status: TaskStatusReview, PullRequest: &forge.PullRequest{Number: 12, State: forge.StateOpen}},
+		{ID: 2, Title: "Add search", Status: TaskStatusReview, PullRequest: &forge.PullRequest{Number: 13, State: forge.StateMerged}},
+	})
+	for _, taskID := range []int{1, 2} {
+		if err := app.startTaskRun("demo", taskID, "", "", "", ""); err != nil {
+			t.Fatalf("Expected task %d to start, got: %v", taskID, err)
+		}
+		// Claude answered the feedback without changing files
+		app.finishTaskRun("demo", taskID, TaskExecutionResult{Success: true, FilesChanged: []string{}})
+	}
+
+	tasks := app.ListTasks("demo").Tasks
+	if tasks[0].Status != TaskStatusReview {
+		t.Errorf("Expected the task with an open pull request to stay in review, got %s", tasks[0].Status)
+	}
+	if tasks[1].Status != TaskStatusInProgress {
+		t.Errorf("Expected the task without an open pull request to be in progress, got %s", tasks[1].Status)
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"specprint/pkg/claude"
+)
+
+// SessionTurn is one prompt sent to Claude and everything that came out of it
+type SessionTurn struct {
+	Prompt          string                  `json:"prompt"`
+	Response        string                  `json:"response,omitempty"` // Everything Claude wrote
+	Summary         string                  `json:"summary,omitempty"`  // The final result Claude reported
+	ToolInvocations []claude.ToolInvocation `json:"toolInvocations,omitempty"`
+	FilesChanged    []string                `json:"filesChanged,omitempty"`
+	CommitHash      string                  `json:"commitHash,omitempty"` // The commit created from the turn's changes, if any
+	Usage           *claude.Usage           `json:"usage,omitempty"`
+	Success         bool                    `json:"success"`
+	Cancelled       bool                    `json:"cancelled,omitempty"`
+	Error           string                  `json:"error,omitempty"`
+	StartedAt       time.Time               `json:"startedAt"`
+	FinishedAt      time.Time               `json:"finishedAt"`
+}
+
+// SessionTranscript is the stored history of one Claude session
+type SessionTranscript struct {
+	SessionID string        `json:"sessionId"`
+	Workspace string        `json:"workspace"`
+	TaskID    int           `json:"taskId"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+	Turns     []SessionTurn `json:"turns"`
+}
+
+// SessionSummary describes a stored session without its turns
+type SessionSummary struct {
+	SessionID   string    `json:"sessionId"`
+	TaskID      int       `json:"taskId"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	TurnCount   int       `json:"turnCount"`
+	LastSummary string    `json:"lastSummary,omitempty"`
+}
+
+// SessionListResult represents the result of listing the sessions of a task
+type SessionListResult struct {
+	Success  bool             `json:"success"`
+	Message  string           `json:"message"`
+	Sessions []SessionSummary `json:"sessions,omitempty"`
+}
+
+// SessionTranscriptResult represents the result of fetching a session transcript
+type SessionTranscriptResult struct {
+	Success    bool               `json:"success"`
+	Message    string             `json:"message"`
+	Transcript *SessionTranscript `json:"transcript,omitempty"`
+}
+
+// validSessionID matches the session IDs Claude Code generates; anything else is rejected so
+// that a session ID can never point outside the session directory
+var validSessionID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// sessionStore persists session transcripts as JSON files under
+// ~/.aicodingtool/sessions/<workspace>/task-<id>/<session>.json
+type sessionStore struct {
+	mu sync.Mutex
+}
+
+// workspaceDir returns the directory holding the sessions of a workspace
+func (s *sessionStore) workspaceDir(workspaceName string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".aicodingtool", "sessions", workspaceName), nil
+}
+
+// taskDir returns the directory holding the sessions of a task
+func (s *sessionStore) taskDir(workspaceName string, taskID int) (string, error) {
+	workspaceDir, err := s.workspaceDir(workspaceName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(workspaceDir, fmt.Sprintf("task-%d", taskID)), nil
+}
+
+// sessionPath returns the path of a session transcript file
+func (s *sessionStore) sessionPath(workspaceName string, taskID int, sessionID string) (string, error) {
+	if !validSessionID.MatchString(sessionID) {
+		return "", fmt.Errorf("invalid session ID: %q", sessionID)
+	}
+	taskDir, err := s.taskDir(workspaceName, taskID)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(taskDir, sessionID+".json"), nil
+}
+
+// AppendTurn adds a turn to a session transcript, creating the transcript if needed
+func (s *sessionStore) AppendTurn(workspaceName string, taskID int, sessionID string, turn SessionTurn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionFile, err := s.sessionPath(workspaceName, taskID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	transcript := SessionTranscript{
+		SessionID: sessionID,
+		Workspace: workspaceName,
+		TaskID:    taskID,
+		CreatedAt: turn.StartedAt,
+		Turns:     []SessionTurn{},
+	}
+	if err := readJSONFile(sessionFile, &transcript); err != nil {
+		return err
+	}
+
+	transcript.Turns = append(transcript.Turns, turn)
+	transcript.UpdatedAt = turn.FinishedAt
+	return writeJSONFile(sessionFile, transcript)
+}
+
+// Get returns a session transcript
+func (s *sessionStore) Get(workspaceName string, taskID int, sessionID string) (SessionTranscript, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionFile, err := s.sessionPath(workspaceName, taskID, sessionID)
+	if err != nil {
+		return SessionTranscript{}, err
+	}
+	if _, err := os.Stat(sessionFile); os.IsNotExist(err) {
+		return SessionTranscript{}, fmt.Errorf("session %s not found for task %d", sessionID, taskID)
+	}
+
+	var transcript SessionTranscript
+	err = readJSONFile(sessionFile, &transcript)
+	return transcript, err
+}
+
+// List returns summaries of the sessions of a task, most recently updated first
+func (s *sessionStore) List(workspaceName string, taskID int) ([]SessionSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	taskDir, err := s.taskDir(workspaceName, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(taskDir)
+	if os.IsNotExist(err) {
+		return []SessionSummary{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []SessionSummary{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		var transcript SessionTranscript
+		if err := readJSONFile(filepath.Join(taskDir, entry.Name()), &transcript); err != nil {
+			fmt.Printf("Warning: Skipping unreadable session %s: %v\n", entry.Name(), err)
+			continue
+		}
+
+		summary := SessionSummary{
+			SessionID: transcript.SessionID,
+			TaskID:    transcript.TaskID,
+			CreatedAt: transcript.CreatedAt,
+			UpdatedAt: transcript.UpdatedAt,
+			TurnCount: len(transcript.Turns),
+		}
+		if len(transcript.Turns) > 0 {
+			summary.LastSummary = transcript.Turns[len(transcript.Turns)-1].Summary
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt)
+	})
+	return summaries, nil
+}
+
+// DeleteTask removes the sessions of a task
+func (s *sessionStore) DeleteTask(workspaceName string, taskID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	taskDir, err := s.taskDir(workspaceName, taskID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(taskDir)
+}
+
+// DeleteWorkspace removes the sessions of every task of a workspace
+func (s *sessionStore) DeleteWorkspace(workspaceName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workspaceDir, err := s.workspaceDir(workspaceName)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(workspaceDir)
+}
+
+// recordSessionTurn stores a finished Claude turn in the transcript of its session, together
+// with the outcome of the run. Failing to store it is logged but does not fail the run.
+func (a *App) recordSessionTurn(workspaceName string, taskID int, worktreePath string, startedAt time.Time, claudeResult claude.TaskExecutionResult, outcome TaskExecutionResult) {
+	if claudeResult.SessionID == "" {
+		return
+	}
+
+	turn := SessionTurn{
+		Prompt:          claudeResult.Prompt,
+		Response:        claudeResult.Transcript,
+		Summary:         claudeResult.Summary,
+		ToolInvocations: claudeResult.ToolInvocations,
+		FilesChanged:    outcome.FilesChanged,
+		Usage:           claudeResult.Usage,
+		Success:         outcome.Success,
+		Cancelled:       outcome.Cancelled,
+		StartedAt:       startedAt,
+		FinishedAt:      time.Now(),
+	}
+	if !outcome.Success {
+		turn.Error = outcome.Message
+	}
+	if outcome.Pushed {
+		turn.CommitHash = worktreeHead(worktreePath)
+	}
+
+	if err := a.sessions.AppendTurn(workspaceName, taskID, claudeResult.SessionID, turn); err != nil {
+		fmt.Printf("Warning: Failed to store session %s of task %d: %v\n", claudeResult.SessionID, taskID, err)
+	}
+}
+
+// worktreeHead returns the commit hash a worktree is at, or "" if it cannot be determined
+func worktreeHead(worktreePath string) string {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// ListTaskSessions returns the stored Claude sessions of a task, most recent first
+func (a *App) ListTaskSessions(workspaceName string, taskID int) SessionListResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return SessionListResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	sessions, err := a.sessions.List(workspaceName, taskID)
+	if err != nil {
+		return SessionListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to list sessions: %v", err),
+		}
+	}
+
+	return SessionListResult{
+		Success:  true,
+		Message:  fmt.Sprintf("Found %d sessions for task %d", len(sessions), taskID),
+		Sessions: sessions,
+	}
+}
+
+// GetSessionTranscript returns the full stored transcript of a Claude session
+func (a *App) GetSessionTranscript(workspaceName string, taskID int, sessionID string) SessionTranscriptResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return SessionTranscriptResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	transcript, err := a.sessions.Get(workspaceName, taskID, sessionID)
+	if err != nil {
+		return SessionTranscriptResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load session: %v", err),
+		}
+	}
+
+	return SessionTranscriptResult{
+		Success:    true,
+		Message:    fmt.Sprintf("Loaded session %s with %d turns", sessionID, len(transcript.Turns)),
+		Transcript: &transcript,
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"specprint/pkg/claude"
+)
+
+func TestSessionTranscriptsAreStoredPerTask(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	started := time.Now().Add(-time.Minute)
+	app.recordSessionTurn("demo", 4, t.TempDir(), started, claude.TaskExecutionResult{
+		SessionID:       "abc-123",
+		Prompt:          "Implement task 4",
+		Transcript:      "Adding the endpoint.",
+		Summary:         "Added the endpoint.",
+		ToolInvocations: []claude.ToolInvocation{{Name: "Write", FilePath: "api.go"}},
+		Usage:           &claude.Usage{InputTokens: 100, OutputTokens: 20},
+	}, TaskExecutionResult{Success: true, FilesChanged: []string{"api.go"}})
+
+	app.recordSessionTurn("demo", 4, t.TempDir(), time.Now(), claude.TaskExecutionResult{
+		SessionID: "abc-123",
+		Prompt:    "Also add tests",
+		Summary:   "Stopped.",
+	}, TaskExecutionResult{Success: false, Cancelled: true, Message: "Claude session was cancelled"})
+
+	// Sessions without an ID cannot be continued and are not stored
+	app.recordSessionTurn("demo", 4, t.TempDir(), time.Now(), claude.TaskExecutionResult{}, TaskExecutionResult{})
+
+	list := app.ListTaskSessions("demo", 4)
+	if !list.Success || len(list.Sessions) != 1 {
+		t.Fatalf("Expected 1 session, got %+v", list)
+	}
+	if summary := list.Sessions[0]; summary.SessionID != "abc-123" || summary.TurnCount != 2 || summary.LastSummary != "Stopped." {
+		t.Errorf("Unexpected session summary: %+v", summary)
+	}
+
+	result := app.GetSessionTranscript("demo", 4, "abc-123")
+	if !result.Success {
+		t.Fatalf("Expected transcript, got: %s", result.Message)
+	}
+	turns := result.Transcript.Turns
+	if len(turns) != 2 {
+		t.Fatalf("Expected 2 turns, got %d", len(turns))
+	}
+	if turns[0].Prompt != "Implement task 4" || turns[0].Response != "Adding the endpoint." || len(turns[0].ToolInvocations) != 1 || turns[0].Usage == nil {
+		t.Errorf("Expected the first turn to be stored in full, got %+v", turns[0])
+	}
+	if turns[1].Success || !turns[1].Cancelled || turns[1].Error == "" {
+		t.Errorf("Expected the second turn to be recorded as cancelled, got %+v", turns[1])
+	}
+	if !result.Transcript.CreatedAt.Equal(started) {
+		t.Errorf("Expected the session to start with its first turn, got %v", result.Transcript.CreatedAt)
+	}
+
+	if other := app.ListTaskSessions("demo", 5); !other.Success || len(other.Sessions) != 0 {
+		t.Errorf("Expected no sessions for another task, got %+v", other)
+	}
+}
+
+func TestGetSessionTranscriptRejectsInvalidIDs(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	if result := app.GetSessionTranscript("demo", 1, "../../tasks/demo"); result.Success {
+		t.Error("Expected a session ID with path separators to be rejected")
+	}
+	if result := app.GetSessionTranscript("demo", 1, "missing"); result.Success {
+		t.Error("Expected an unknown session to be reported")
+	}
+}
+
+func TestSessionTurnRecordsCommitPushedDespiteFailedVerification(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	worktreePath := t.TempDir()
+	for _, args := range [][]string{{"init", "-q"}, {"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "Add search"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = worktreePath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	// onFailure "mark" commits and pushes, but fails the run
+	app.recordSessionTurn("demo", 4, worktreePath, time.Now(), claude.TaskExecutionResult{SessionID: "abc-123"}, TaskExecutionResult{
+		Success:      false,
+		Message:      "Committed and pushed, but verification step 'test' failed",
+		FilesChanged: []string{"search.go"},
+		Pushed:       true,
+	})
+
+	result := app.GetSessionTranscript("demo", 4, "abc-123")
+	if !result.Success || len(result.Transcript.Turns) != 1 {
+		t.Fatalf("Expected one turn, got %+v", result)
+	}
+	if turn := result.Transcript.Turns[0]; turn.CommitHash != worktreeHead(worktreePath) || turn.CommitHash == "" {
+		t.Errorf("Expected the pushed commit to be recorded, got %q", turn.CommitHash)
+	}
+}
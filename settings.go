@@ -0,0 +1,385 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"specprint/pkg/claude"
+	"specprint/pkg/forge"
+	"specprint/pkg/llm"
+)
+
+// Settings holds app-wide settings, stored in ~/.aicodingtool/settings.json
+type Settings struct {
+	ClaudeRun      claude.RunConfig   `json:"claudeRun"`      // Defaults for every Claude session
+	TaskGeneration llm.ProviderConfig `json:"taskGeneration"` // Provider used to generate tasks from PRDs
+	Forge          forge.Config       `json:"forge"`          // Forge pull requests are opened on; detected from the remote by default
+	// PullRequestSyncSeconds is how often open pull requests are checked to update their tasks.
+	// 0 uses the default interval and a negative value turns the background sync off.
+	PullRequestSyncSeconds int `json:"pullRequestSyncSeconds,omitempty"`
+}
+
+// WorkspaceSettings holds settings that override the app-wide ones for a single workspace
+type WorkspaceSettings struct {
+	Workspace      string             `json:"workspace"`
+	ClaudeRun      claude.RunConfig   `json:"claudeRun"`      // Overrides Settings.ClaudeRun for the workspace's tasks
+	TaskGeneration llm.ProviderConfig `json:"taskGeneration"` // Replaces Settings.TaskGeneration when it names a provider
+	Verification   VerificationConfig `json:"verification"`   // Checks run on a task's changes before they are committed
+	Forge          forge.Config       `json:"forge"`          // Replaces Settings.Forge when it names a provider or base URL
+	// AutoPullRequest opens a pull request into the base branch whenever a task run pushes changes
+	AutoPullRequest bool           `json:"autoPullRequest,omitempty"`
+	CommitIdentity  CommitIdentity `json:"commitIdentity"` // Who task commits are attributed to and whether they are signed
+}
+
+// SettingsResult represents the result of reading or saving the app-wide settings
+type SettingsResult struct {
+	Success  bool      `json:"success"`
+	Message  string    `json:"message"`
+	Settings *Settings `json:"settings,omitempty"`
+}
+
+// WorkspaceSettingsResult represents the result of reading or saving the settings of a workspace
+type WorkspaceSettingsResult struct {
+	Success  bool               `json:"success"`
+	Message  string             `json:"message"`
+	Settings *WorkspaceSettings `json:"settings,omitempty"`
+}
+
+// settingsStore persists settings as JSON files under ~/.aicodingtool
+type settingsStore struct {
+	mu sync.Mutex
+}
+
+// globalPath returns the path of the app-wide settings file
+func (s *settingsStore) globalPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".aicodingtool", "settings.json"), nil
+}
+
+// workspacePath returns the path of the settings file of a workspace
+func (s *settingsStore) workspacePath(workspaceName string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".aicodingtool", "settings", workspaceName+".json"), nil
+}
+
+// LoadGlobal returns the app-wide settings, or empty settings if none were saved
+func (s *settingsStore) LoadGlobal() (Settings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var settings Settings
+	settingsFile, err := s.globalPath()
+	if err != nil {
+		return settings, err
+	}
+	err = readJSONFile(settingsFile, &settings)
+	return settings, err
+}
+
+// SaveGlobal writes the app-wide settings
+func (s *settingsStore) SaveGlobal(settings Settings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settingsFile, err := s.globalPath()
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(settingsFile, settings)
+}
+
+// LoadWorkspace returns the settings of a workspace, or empty settings if none were saved
+func (s *settingsStore) LoadWorkspace(workspaceName string) (WorkspaceSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := WorkspaceSettings{Workspace: workspaceName}
+	settingsFile, err := s.workspacePath(workspaceName)
+	if err != nil {
+		return settings, err
+	}
+	err = readJSONFile(settingsFile, &settings)
+	settings.Workspace = workspaceName
+	return settings, err
+}
+
+// SaveWorkspace writes the settings of a workspace
+func (s *settingsStore) SaveWorkspace(settings WorkspaceSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settingsFile, err := s.workspacePath(settings.Workspace)
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(settingsFile, settings)
+}
+
+// DeleteWorkspace removes the settings of a workspace
+func (s *settingsStore) DeleteWorkspace(workspaceName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settingsFile, err := s.workspacePath(workspaceName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(settingsFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// readJSONFile decodes a JSON file into v, leaving v untouched if the file does not exist
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return nil
+}
+
+// writeJSONFile encodes v as indented JSON and replaces path with it atomically
+func writeJSONFile(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, path)
+}
+
+// GetSettings returns the app-wide settings
+func (a *App) GetSettings() SettingsResult {
+	settings, err := a.settings.LoadGlobal()
+	if err != nil {
+		return SettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load settings: %v", err),
+		}
+	}
+
+	return SettingsResult{
+		Success:  true,
+		Message:  "Loaded settings",
+		Settings: &settings,
+	}
+}
+
+// SaveSettings replaces the app-wide settings
+func (a *App) SaveSettings(settings Settings) SettingsResult {
+	if err := settings.ClaudeRun.Validate(); err != nil {
+		return SettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Invalid Claude run config: %v", err),
+		}
+	}
+
+	if err := settings.TaskGeneration.Validate(); err != nil {
+		return SettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Invalid task generation provider: %v", err),
+		}
+	}
+
+	if err := settings.Forge.Validate(); err != nil {
+		return SettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Invalid forge config: %v", err),
+		}
+	}
+
+	if err := a.settings.SaveGlobal(settings); err != nil {
+		return SettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save settings: %v", err),
+		}
+	}
+
+	return SettingsResult{
+		Success:  true,
+		Message:  "Saved settings",
+		Settings: &settings,
+	}
+}
+
+// GetWorkspaceSettings returns the settings of a workspace
+func (a *App) GetWorkspaceSettings(workspaceName string) WorkspaceSettingsResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return WorkspaceSettingsResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	settings, err := a.settings.LoadWorkspace(workspaceName)
+	if err != nil {
+		return WorkspaceSettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load workspace settings: %v", err),
+		}
+	}
+
+	return WorkspaceSettingsResult{
+		Success:  true,
+		Message:  fmt.Sprintf("Loaded settings of workspace '%s'", workspaceName),
+		Settings: &settings,
+	}
+}
+
+// SaveWorkspaceSettings replaces the settings of a workspace
+func (a *App) SaveWorkspaceSettings(workspaceName string, settings WorkspaceSettings) WorkspaceSettingsResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return WorkspaceSettingsResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	if err := settings.ClaudeRun.Validate(); err != nil {
+		return WorkspaceSettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Invalid Claude run config: %v", err),
+		}
+	}
+
+	if err := settings.TaskGeneration.Validate(); err != nil {
+		return WorkspaceSettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Invalid task generation provider: %v", err),
+		}
+	}
+
+	if err := settings.Forge.Validate(); err != nil {
+		return WorkspaceSettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Invalid forge config: %v", err),
+		}
+	}
+
+	if err := settings.Verification.Validate(); err != nil {
+		return WorkspaceSettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Invalid verification config: %v", err),
+		}
+	}
+
+	if err := settings.CommitIdentity.Validate(); err != nil {
+		return WorkspaceSettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Invalid commit identity: %v", err),
+		}
+	}
+
+	settings.Workspace = workspaceName
+	if err := a.settings.SaveWorkspace(settings); err != nil {
+		return WorkspaceSettingsResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save workspace settings: %v", err),
+		}
+	}
+
+	return WorkspaceSettingsResult{
+		Success:  true,
+		Message:  fmt.Sprintf("Saved settings of workspace '%s'", workspaceName),
+		Settings: &settings,
+	}
+}
+
+// claudeRunConfig layers the app-wide, workspace and task run configs. An empty workspace
+// name or a task ID of 0 skips the respective level.
+func (a *App) claudeRunConfig(workspaceName string, taskID int) (claude.RunConfig, error) {
+	global, err := a.settings.LoadGlobal()
+	if err != nil {
+		return claude.RunConfig{}, fmt.Errorf("failed to load settings: %v", err)
+	}
+	config := global.ClaudeRun
+
+	if workspaceName == "" {
+		return config, nil
+	}
+
+	workspaceSettings, err := a.settings.LoadWorkspace(workspaceName)
+	if err != nil {
+		return claude.RunConfig{}, fmt.Errorf("failed to load workspace settings: %v", err)
+	}
+	config = config.Merge(workspaceSettings.ClaudeRun)
+
+	if taskID == 0 {
+		return config, nil
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return claude.RunConfig{}, fmt.Errorf("failed to load tasks: %v", err)
+	}
+	if index := board.findTask(taskID); index >= 0 && board.Tasks[index].RunConfig != nil {
+		config = config.Merge(*board.Tasks[index].RunConfig)
+	}
+
+	return config, nil
+}
+
+// forgeConfig returns the forge config of a workspace. The workspace config replaces the
+// app-wide one when it names a provider or base URL.
+func (a *App) forgeConfig(workspaceName string) (forge.Config, error) {
+	global, err := a.settings.LoadGlobal()
+	if err != nil {
+		return forge.Config{}, fmt.Errorf("failed to load settings: %v", err)
+	}
+
+	workspaceSettings, err := a.settings.LoadWorkspace(workspaceName)
+	if err != nil {
+		return forge.Config{}, fmt.Errorf("failed to load workspace settings: %v", err)
+	}
+	if workspaceSettings.Forge.Provider != "" || workspaceSettings.Forge.BaseURL != "" {
+		return workspaceSettings.Forge, nil
+	}
+	return global.Forge, nil
+}
+
+// taskGenerator creates the LLM provider that generates tasks for a workspace. The workspace's
+// provider config replaces the app-wide one when it names a provider; an empty workspace name
+// uses the app-wide config.
+func (a *App) taskGenerator(workspaceName string) (llm.TaskGenerator, error) {
+	global, err := a.settings.LoadGlobal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %v", err)
+	}
+	config := global.TaskGeneration
+
+	if workspaceName != "" {
+		workspaceSettings, err := a.settings.LoadWorkspace(workspaceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load workspace settings: %v", err)
+		}
+		if workspaceSettings.TaskGeneration.Provider != "" {
+			config = workspaceSettings.TaskGeneration
+		}
+	}
+
+	return llm.NewTaskGenerator(config)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"specprint/pkg/claude"
+	"specprint/pkg/llm"
+)
+
+func TestClaudeRunConfigLayersSettings(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	if result := app.SaveSettings(Settings{ClaudeRun: claude.RunConfig{MaxTurns: 20, TimeoutSeconds: 900}}); !result.Success {
+		t.Fatalf("Expected settings to be saved, got: %s", result.Message)
+	}
+	if result := app.SaveWorkspaceSettings("demo", WorkspaceSettings{ClaudeRun: claude.RunConfig{MaxTurns: 15, PermissionMode: "plan"}}); !result.Success {
+		t.Fatalf("Expected workspace settings to be saved, got: %s", result.Message)
+	}
+
+	created := app.CreateTask("demo", Task{Title: "Add search"})
+	task := *created.Task
+	task.RunConfig = &claude.RunConfig{AllowedTools: []string{"Read"}}
+	if result := app.UpdateTask("demo", task); !result.Success {
+		t.Fatalf("Expected task to be updated, got: %s", result.Message)
+	}
+
+	config, err := app.claudeRunConfig("demo", task.ID)
+	if err != nil {
+		t.Fatalf("Expected run config, got: %v", err)
+	}
+	expected := claude.RunConfig{MaxTurns: 15, TimeoutSeconds: 900, PermissionMode: "plan", AllowedTools: []string{"Read"}}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, config)
+	}
+
+	// Sessions outside the task board only get the app-wide config
+	config, err = app.claudeRunConfig("", 0)
+	if err != nil || config.MaxTurns != 20 || config.PermissionMode != "" {
+		t.Errorf("Expected the app-wide config, got %+v (%v)", config, err)
+	}
+}
+
+func TestSaveSettingsRejectsInvalidRunConfig(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	if result := app.SaveSettings(Settings{ClaudeRun: claude.RunConfig{PermissionMode: "yolo"}}); result.Success {
+		t.Error("Expected invalid permission mode to be rejected")
+	}
+	if result := app.SaveWorkspaceSettings("demo", WorkspaceSettings{ClaudeRun: claude.RunConfig{MaxTurns: -1}}); result.Success {
+		t.Error("Expected negative max turns to be rejected")
+	}
+	if result := app.GetWorkspaceSettings("missing"); result.Success {
+		t.Error("Expected unknown workspace to be rejected")
+	}
+}
+
+func TestTaskGeneratorUsesWorkspaceProvider(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+
+	generator, err := app.taskGenerator("demo")
+	if err != nil || generator.Name() != "OpenAI" {
+		t.Fatalf("Expected OpenAI by default, got %v (%v)", generator, err)
+	}
+
+	if result := app.SaveWorkspaceSettings("demo", WorkspaceSettings{TaskGeneration: llm.ProviderConfig{Provider: llm.ProviderAnthropic}}); !result.Success {
+		t.Fatalf("Expected workspace settings to be saved, got: %s", result.Message)
+	}
+	if generator, err = app.taskGenerator("demo"); err != nil || generator.Name() != "Anthropic" {
+		t.Errorf("Expected the workspace provider, got %v (%v)", generator, err)
+	}
+	if generator, err = app.taskGenerator(""); err != nil || generator.Name() != "OpenAI" {
+		t.Errorf("Expected the app-wide provider outside workspaces, got %v (%v)", generator, err)
+	}
+
+	if result := app.SaveSettings(Settings{TaskGeneration: llm.ProviderConfig{Provider: llm.ProviderAzureOpenAI}}); result.Success {
+		t.Error("Expected Azure without endpoint to be rejected")
+	}
+}
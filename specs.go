@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSpecID identifies the PRD.md at the root of a workspace. Named specs live in
+// specs/<id>.md.
+const DefaultSpecID = ""
+
+// specsDir is where named spec documents live inside a workspace
+const specsDir = "specs"
+
+// specIDPattern matches valid names of spec documents, e.g. "checkout" or "search-v2"
+var specIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// SpecDocument describes one spec document of a workspace
+type SpecDocument struct {
+	ID        string    `json:"id"` // Empty for the workspace's PRD.md
+	Title     string    `json:"title"`
+	Path      string    `json:"path"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	TaskCount int       `json:"taskCount"` // Tasks on the board that belong to the spec
+}
+
+// SpecListResult represents the result of listing the spec documents of a workspace
+type SpecListResult struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Specs   []SpecDocument `json:"specs,omitempty"`
+}
+
+// SpecResult represents the result of reading a spec document
+type SpecResult struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Spec    *SpecDocument `json:"spec,omitempty"`
+	Content string        `json:"content,omitempty"`
+}
+
+// SpecSource is the spec content a set of tasks was generated from
+type SpecSource struct {
+	Content string `json:"content"`
+	Version int    `json:"version,omitempty"` // The version of Content in the spec's history
+}
+
+// validateSpecID returns an error if a spec ID cannot be used as a file name
+func validateSpecID(specID string) error {
+	if specID == DefaultSpecID || (specIDPattern.MatchString(specID) && !strings.HasSuffix(specID, ".md")) {
+		return nil
+	}
+	return fmt.Errorf("Invalid spec name '%s': use letters, digits, '.', '-' and '_'", specID)
+}
+
+// specPath returns the file of a spec document in a workspace
+func specPath(workspacePath, specID string) string {
+	if specID == DefaultSpecID {
+		return filepath.Join(workspacePath, "PRD.md")
+	}
+	return filepath.Join(workspacePath, specsDir, specID+".md")
+}
+
+// specLabel names a spec document in messages
+func specLabel(specID string) string {
+	if specID == DefaultSpecID {
+		return "the PRD"
+	}
+	return fmt.Sprintf("spec '%s'", specID)
+}
+
+// specTitle returns the first markdown heading of a spec document, or fallback if it has none
+func specTitle(path, fallback string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return fallback
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if isMarkdownHeading(line) {
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+	}
+	return fallback
+}
+
+// findSpecWorkspace looks up a workspace and validates a spec ID for it
+func (a *App) findSpecWorkspace(workspaceName, specID string) (*Workspace, error) {
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSpecID(specID); err != nil {
+		return nil, err
+	}
+	return targetWorkspace, nil
+}
+
+// source returns the content and version a spec's tasks were generated from
+func (b *TaskBoard) source(specID string) SpecSource {
+	if specID == DefaultSpecID {
+		return SpecSource{Content: b.SourcePRD, Version: b.SourcePRDVersion}
+	}
+	return b.SpecSources[specID]
+}
+
+// setSource records the content and version a spec's tasks were generated from
+func (b *TaskBoard) setSource(specID, content string, version int) {
+	if specID == DefaultSpecID {
+		b.SourcePRD = content
+		b.SourcePRDVersion = version
+		return
+	}
+	if b.SpecSources == nil {
+		b.SpecSources = make(map[string]SpecSource)
+	}
+	b.SpecSources[specID] = SpecSource{Content: content, Version: version}
+}
+
+// specTasks returns the tasks of the board that belong to a spec
+func (b *TaskBoard) specTasks(specID string) []Task {
+	tasks := []Task{}
+	for _, task := range b.Tasks {
+		if task.Spec == specID {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// checkSpecIdle returns an error if a task of a spec is running, as replacing it would leave
+// the run without its task
+func (b *TaskBoard) checkSpecIdle(specID string) error {
+	for _, task := range b.Tasks {
+		if task.Spec == specID && task.Status == TaskStatusRunning {
+			return fmt.Errorf("task %d of %s is running; wait for it to finish or cancel it first", task.ID, specLabel(specID))
+		}
+	}
+	return nil
+}
+
+// replaceSpecTasks replaces the tasks of a spec with newly generated ones and returns them as
+// stored. The new tasks are renumbered above every current ID, so they never take over the
+// branches, worktrees and sessions of the tasks they replace, and dependencies of other specs'
+// tasks on the replaced tasks are dropped.
+func (b *TaskBoard) replaceSpecTasks(specID string, tasks []Task) ([]Task, error) {
+	if err := b.checkSpecIdle(specID); err != nil {
+		return nil, err
+	}
+
+	offset := b.nextTaskID() - 1
+	removed := make(map[int]bool)
+	var remaining []Task
+	for _, task := range b.Tasks {
+		if task.Spec == specID {
+			removed[task.ID] = true
+			continue
+		}
+		remaining = append(remaining, task)
+	}
+
+	for i := range remaining {
+		dependencies := make([]int, 0, len(remaining[i].Dependencies))
+		for _, depID := range remaining[i].Dependencies {
+			if !removed[depID] {
+				dependencies = append(dependencies, depID)
+			}
+		}
+		remaining[i].Dependencies = dependencies
+	}
+
+	renumbered := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		task.ID += offset
+		dependencies := make([]int, 0, len(task.Dependencies))
+		for _, depID := range task.Dependencies {
+			dependencies = append(dependencies, depID+offset)
+		}
+		task.Dependencies = dependencies
+		renumbered = append(renumbered, task)
+	}
+
+	b.Tasks = append(remaining, renumbered...)
+	return renumbered, nil
+}
+
+// ListWorkspaceSpecs returns the PRD.md and every document in specs/ of a workspace
+func (a *App) ListWorkspaceSpecs(workspaceName string) SpecListResult {
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
+		return SpecListResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return SpecListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load tasks: %v", err),
+		}
+	}
+	taskCounts := make(map[string]int)
+	for _, task := range board.Tasks {
+		taskCounts[task.Spec]++
+	}
+
+	specIDs := []string{DefaultSpecID}
+	entries, err := os.ReadDir(filepath.Join(targetWorkspace.Path, specsDir))
+	if err != nil && !os.IsNotExist(err) {
+		return SpecListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to read specs directory: %v", err),
+		}
+	}
+	var named []string
+	for _, entry := range entries {
+		specID := strings.TrimSuffix(entry.Name(), ".md")
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") || validateSpecID(specID) != nil {
+			continue
+		}
+		named = append(named, specID)
+	}
+	sort.Strings(named)
+	specIDs = append(specIDs, named...)
+
+	var specs []SpecDocument
+	for _, specID := range specIDs {
+		path := specPath(targetWorkspace.Path, specID)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		fallback := specID
+		if specID == DefaultSpecID {
+			fallback = "PRD"
+		}
+		specs = append(specs, SpecDocument{
+			ID:        specID,
+			Title:     specTitle(path, fallback),
+			Path:      path,
+			UpdatedAt: info.ModTime(),
+			TaskCount: taskCounts[specID],
+		})
+	}
+
+	return SpecListResult{
+		Success: true,
+		Message: fmt.Sprintf("Found %d specs in workspace '%s'", len(specs), workspaceName),
+		Specs:   specs,
+	}
+}
+
+// GetWorkspaceSpec returns the content of a spec document
+func (a *App) GetWorkspaceSpec(workspaceName, specID string) SpecResult {
+	targetWorkspace, err := a.findSpecWorkspace(workspaceName, specID)
+	if err != nil {
+		return SpecResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	path := specPath(targetWorkspace.Path, specID)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return SpecResult{
+			Success: false,
+			Message: fmt.Sprintf("Workspace '%s' does not have %s", workspaceName, specLabel(specID)),
+		}
+	}
+	if err != nil {
+		return SpecResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to read spec: %v", err),
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return SpecResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to read spec: %v", err),
+		}
+	}
+
+	return SpecResult{
+		Success: true,
+		Message: fmt.Sprintf("Loaded %s", specLabel(specID)),
+		Spec:    &SpecDocument{ID: specID, Title: specTitle(path, specID), Path: path, UpdatedAt: info.ModTime()},
+		Content: string(content),
+	}
+}
+
+// SaveWorkspaceSpec writes a spec document of a workspace. Named specs are stored as given in
+// specs/<id>.md; the PRD.md is saved like SaveWorkspacePRD does. Every save is recorded in the
+// spec's history.
+func (a *App) SaveWorkspaceSpec(workspaceName, specID, content string) PRDResult {
+	if specID == DefaultSpecID {
+		return a.SaveWorkspacePRD(workspaceName, content)
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return PRDResult{
+			Success: false,
+			Message: "Spec content cannot be empty",
+		}
+	}
+
+	targetWorkspace, err := a.findSpecWorkspace(workspaceName, specID)
+	if err != nil {
+		return PRDResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	// Keep the spec being replaced, in case it was edited outside the app
+	if _, _, err := a.recordCurrentSpec(targetWorkspace, specID); err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to record the current spec: %v", err),
+		}
+	}
+
+	path := specPath(targetWorkspace.Path, specID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to create specs directory: %v", err),
+		}
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to write spec: %v", err),
+		}
+	}
+
+	version, err := a.prdHistory.Record(targetWorkspace.Path, specID, content, PRDSourceSaved, 0)
+	if err != nil {
+		return PRDResult{
+			Success: false,
+			Message: fmt.Sprintf("Spec saved but failed to record its version: %v", err),
+			Path:    path,
+		}
+	}
+
+	return PRDResult{
+		Success: true,
+		Message: fmt.Sprintf("Spec '%s' saved successfully to workspace: %s", specID, workspaceName),
+		Path:    path,
+		Version: version.Version,
+	}
+}
+
+// ListSpecTasks returns the tasks of a workspace that belong to one spec, in board order
+func (a *App) ListSpecTasks(workspaceName, specID string) TaskListResult {
+	if _, err := a.findSpecWorkspace(workspaceName, specID); err != nil {
+		return TaskListResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return TaskListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load tasks: %v", err),
+		}
+	}
+
+	tasks := board.specTasks(specID)
+	return TaskListResult{
+		Success:     true,
+		Message:     fmt.Sprintf("Found %d tasks for %s in workspace '%s'", len(tasks), specLabel(specID), workspaceName),
+		Tasks:       tasks,
+		LastUpdated: board.LastUpdated,
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspaceSpecsSaveAndList(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+	workspacePath := filepath.Join(os.Getenv("HOME"), ".aicodingtool", "repos", "demo")
+	os.WriteFile(filepath.Join(workspacePath, "PRD.md"), []byte("# Product\nLogin\n"), 0644)
+
+	saved := app.SaveWorkspaceSpec("demo", "checkout", "# Checkout flow\nPay by card\n")
+	if !saved.Success || saved.Version != 1 {
+		t.Fatalf("Expected the spec to be saved as version 1, got %+v", saved)
+	}
+	if content, _ := os.ReadFile(filepath.Join(workspacePath, "specs", "checkout.md")); string(content) != "# Checkout flow\nPay by card\n" {
+		t.Errorf("Expected the spec to be written verbatim, got %q", content)
+	}
+
+	app.tasks.Update("demo", func(board *TaskBoard) error {
+		board.Tasks = []Task{
+			{ID: 1, Title: "Login", Dependencies: []int{}},
+			{ID: 2, Title: "Card form", Dependencies: []int{}, Spec: "checkout"},
+		}
+		return nil
+	})
+
+	list := app.ListWorkspaceSpecs("demo")
+	if !list.Success || len(list.Specs) != 2 {
+		t.Fatalf("Expected PRD.md and the checkout spec, got %+v", list)
+	}
+	if list.Specs[0].ID != DefaultSpecID || list.Specs[0].Title != "Product" || list.Specs[0].TaskCount != 1 {
+		t.Errorf("Expected the PRD first, got %+v", list.Specs[0])
+	}
+	if list.Specs[1].ID != "checkout" || list.Specs[1].Title != "Checkout flow" || list.Specs[1].TaskCount != 1 {
+		t.Errorf("Expected the checkout spec second, got %+v", list.Specs[1])
+	}
+
+	tasks := app.ListSpecTasks("demo", "checkout")
+	if !tasks.Success || len(tasks.Tasks) != 1 || tasks.Tasks[0].ID != 2 {
+		t.Errorf("Expected only the checkout task, got %+v", tasks)
+	}
+
+	history := app.ListPRDVersions("demo", "checkout")
+	if !history.Success || len(history.Versions) != 1 {
+		t.Errorf("Expected the spec to have its own history, got %+v", history)
+	}
+}
+
+func TestWorkspaceSpecsRejectInvalidIDs(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	for _, specID := range []string{"../escape", "a/b", ".hidden", "notes.md"} {
+		if result := app.SaveWorkspaceSpec("demo", specID, "# Spec\n"); result.Success {
+			t.Errorf("Expected spec name %q to be rejected", specID)
+		}
+	}
+}
+
+func TestReplaceSpecTasksKeepsOtherSpecs(t *testing.T) {
+	board := TaskBoard{Tasks: []Task{
+		{ID: 1, Title: "Login", Dependencies: []int{}},
+		{ID: 2, Title: "Old card form", Dependencies: []int{1}, Spec: "checkout"},
+		{ID: 3, Title: "Profile", Dependencies: []int{1, 2}},
+	}}
+
+	stored, err := board.replaceSpecTasks("checkout", []Task{
+		{ID: 1, Title: "Card form", Dependencies: []int{}, Spec: "checkout"},
+		{ID: 2, Title: "Receipts", Dependencies: []int{1}, Spec: "checkout"},
+	})
+
+	if err != nil || len(stored) != 2 || stored[0].ID != 4 || stored[1].ID != 5 || stored[1].Dependencies[0] != 4 {
+		t.Fatalf("Expected the new tasks to be renumbered above the remaining ones, got %+v", stored)
+	}
+	if len(board.Tasks) != 4 || board.Tasks[1].ID != 3 {
+		t.Fatalf("Expected the other spec's tasks to be kept, got %+v", board.Tasks)
+	}
+	if deps := board.Tasks[1].Dependencies; len(deps) != 1 || deps[0] != 1 {
+		t.Errorf("Expected the dependency on the replaced task to be dropped, got %v", deps)
+	}
+}
+
+func TestReplaceSpecTasksRefusesRunningTasks(t *testing.T) {
+	board := TaskBoard{Tasks: []Task{
+		{ID: 1, Title: "Card form", Dependencies: []int{}, Spec: "checkout", Status: TaskStatusRunning},
+		{ID: 2, Title: "Receipts", Dependencies: []int{1}, Spec: "checkout"},
+	}}
+
+	if _, err := board.replaceSpecTasks("checkout", []Task{{ID: 1, Title: "New card form", Dependencies: []int{}}}); err == nil {
+		t.Fatal("Expected replacing a spec with a running task to be refused")
+	}
+	if len(board.Tasks) != 2 || board.Tasks[0].Title != "Card form" {
+		t.Errorf("Expected the board to be unchanged, got %+v", board.Tasks)
+	}
+
+	// Once idle, the new tasks get IDs no replaced task had, so they never share its branch,
+	// worktree or sessions
+	board.Tasks[0].Status = TaskStatusReview
+	stored, err := board.replaceSpecTasks("checkout", []Task{{ID: 1, Title: "New card form", Dependencies: []int{}}})
+	if err != nil || len(stored) != 1 || stored[0].ID != 3 {
+		t.Errorf("Expected the new task to be numbered above the replaced ones, got %+v (%v)", stored, err)
+	}
+}
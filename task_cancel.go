@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// taskRunKey identifies a task run in the cancellation registry
+func taskRunKey(workspaceName string, taskID int) string {
+	return fmt.Sprintf("%s/%d", workspaceName, taskID)
+}
+
+// beginTaskRun registers a cancellable context for a task run. It is called before the task is
+// marked as running, so CancelTask always finds the run of a running task; it fails if the task
+// already has a run. The returned function must be called once the run is over.
+func (a *App) beginTaskRun(workspaceName string, taskID int) (context.Context, func(), error) {
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	key := taskRunKey(workspaceName, taskID)
+	a.cancelsMu.Lock()
+	if _, running := a.taskCancels[key]; running {
+		a.cancelsMu.Unlock()
+		return nil, nil, fmt.Errorf("task %d is already running", taskID)
+	}
+	ctx, cancel := context.WithCancel(parent)
+	if a.taskCancels == nil {
+		a.taskCancels = make(map[string]context.CancelFunc)
+	}
+	a.taskCancels[key] = cancel
+	a.cancelsMu.Unlock()
+
+	return ctx, func() {
+		a.cancelsMu.Lock()
+		delete(a.taskCancels, key)
+		a.cancelsMu.Unlock()
+		cancel()
+	}, nil
+}
+
+// CancelTask stops the running Claude session of a task. The run discards its uncommitted
+// changes and marks the task as cancelled once Claude Code has exited.
+func (a *App) CancelTask(workspaceName string, taskID int) TaskResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return TaskResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	a.cancelsMu.Lock()
+	cancel, running := a.taskCancels[taskRunKey(workspaceName, taskID)]
+	a.cancelsMu.Unlock()
+
+	if running {
+		cancel()
+		return TaskResult{
+			Success: true,
+			Message: fmt.Sprintf("Cancelling task %d", taskID),
+		}
+	}
+
+	// A task can be left running without an active run, e.g. when the app exited mid-run
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return TaskResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load tasks: %v", err),
+		}
+	}
+	if index := board.findTask(taskID); index < 0 || board.Tasks[index].Status != TaskStatusRunning {
+		return TaskResult{
+			Success: false,
+			Message: fmt.Sprintf("Task %d is not running", taskID),
+		}
+	}
+
+	task, err := a.transitionTask(workspaceName, taskID, TaskStatusCancelled, nil)
+	if err != nil {
+		return TaskResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to cancel task %d: %v", taskID, err),
+		}
+	}
+
+	return TaskResult{
+		Success: true,
+		Message: fmt.Sprintf("Task %d had no active run and was marked as cancelled", taskID),
+		Task:    &task,
+	}
+}
+
+// discardWorktreeChanges resets a worktree to its last commit and removes untracked files
+func discardWorktreeChanges(worktreePath string) error {
+	for _, args := range [][]string{
+		{"reset", "--hard", "HEAD"},
+		{"clean", "-fd"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = worktreePath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
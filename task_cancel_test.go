@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCancelTaskCancelsActiveRun(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	ctx, release, err := app.beginTaskRun("demo", 1)
+	if err != nil {
+		t.Fatalf("Expected the run to be registered, got: %v", err)
+	}
+	if _, _, err := app.beginTaskRun("demo", 1); err == nil {
+		t.Error("Expected a second run of the same task to be rejected")
+	}
+
+	// A run is cancellable before its task is marked as running
+	if result := app.CancelTask("demo", 1); !result.Success || ctx.Err() == nil {
+		t.Fatalf("Expected the starting run to be cancelled, got %+v", result)
+	}
+	if err := app.startTaskRun("demo", 1, "Add search", "Full-text search", "", ""); err != nil {
+		t.Fatalf("Expected task to start, got: %v", err)
+	}
+
+	result := app.CancelTask("demo", 1)
+	if !result.Success {
+		t.Fatalf("Expected cancel to succeed, got: %s", result.Message)
+	}
+	if ctx.Err() == nil {
+		t.Error("Expected the run context to be cancelled")
+	}
+
+	release()
+	app.finishTaskRun("demo", 1, TaskExecutionResult{Success: false, Cancelled: true, Message: "Task 1 was cancelled"})
+
+	task := app.ListTasks("demo").Tasks[0]
+	if task.Status != TaskStatusCancelled || task.LastError != "" {
+		t.Errorf("Expected cancelled task without an error, got status %s and error %q", task.Status, task.LastError)
+	}
+
+	if result := app.CancelTask("demo", 1); result.Success {
+		t.Error("Expected cancelling a task that is not running to fail")
+	}
+}
+
+func TestCancelTaskMarksStaleRunCancelled(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	// A task left running without a registered run, e.g. after a restart
+	if err := app.startTaskRun("demo", 2, "Add login", "", "", ""); err != nil {
+		t.Fatalf("Expected task to start, got: %v", err)
+	}
+
+	result := app.CancelTask("demo", 2)
+	if !result.Success || result.Task == nil || result.Task.Status != TaskStatusCancelled {
+		t.Fatalf("Expected stale run to be marked cancelled, got %+v", result)
+	}
+
+	if moved := app.MoveTask("demo", 2, TaskStatusCancelled, 0); moved.Success {
+		t.Error("Expected moving a task to 'cancelled' to be rejected")
+	}
+	if moved := app.MoveTask("demo", 2, TaskStatusTodo, 0); !moved.Success {
+		t.Errorf("Expected cancelled task to move back to todo, got: %s", moved.Message)
+	}
+}
+
+func TestDiscardWorktreeChanges(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "main.go")
+	run("commit", "-q", "-m", "Initial commit")
+
+	// Simulate a half-done edit and a new file
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc broken(\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0644)
+
+	if err := discardWorktreeChanges(dir); err != nil {
+		t.Fatalf("Expected changes to be discarded, got: %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "main.go"))
+	if string(content) != "package main\n" {
+		t.Errorf("Expected main.go to be restored, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.go")); !os.IsNotExist(err) {
+		t.Error("Expected untracked file to be removed")
+	}
+}
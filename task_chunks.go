@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"specprint/pkg/llm"
+)
+
+// Event emitted to the frontend while the parts of a long PRD are turned into tasks
+const TaskGenerationProgressEvent = "taskgeneration:progress"
+
+// taskGenerationMaxTokens bounds the reply to a single task generation request
+const taskGenerationMaxTokens = 4096
+
+// defaultPRDChunkChars is the PRD size above which generation is split by markdown section
+const defaultPRDChunkChars = 8000
+
+// maxParallelChunks bounds how many parts of a PRD are sent to the provider at once
+const maxParallelChunks = 4
+
+// States reported in TaskGenerationProgress
+const (
+	ChunkQueued    = "queued"
+	ChunkStarted   = "started"
+	ChunkSucceeded = "succeeded"
+	ChunkFailed    = "failed"
+	ChunkLinking   = "linking" // Cross-section dependencies are being resolved after all parts finished
+)
+
+// TaskGenerationProgress describes a state change of one part of a PRD during chunked generation
+type TaskGenerationProgress struct {
+	Workspace string `json:"workspace,omitempty"`
+	Chunk     int    `json:"chunk"`  // 1-based index of the part; 0 for the linking step
+	Chunks    int    `json:"chunks"` // Number of parts the PRD was split into
+	Section   string `json:"section,omitempty"`
+	State     string `json:"state"`
+	TaskCount int    `json:"taskCount,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// prdChunk is a run of consecutive PRD sections that is generated in one request
+type prdChunk struct {
+	Heading string // Heading of the first section in the chunk
+	Content string
+}
+
+// prdSection is a markdown heading and the text up to the next heading
+type prdSection struct {
+	heading string
+	content string
+}
+
+// splitPRD splits a PRD into chunks of at most maxChars characters. Chunks are built from whole
+// markdown sections where possible; sections that are too long on their own are split at
+// paragraph boundaries.
+func splitPRD(prdContent string, maxChars int) []prdChunk {
+	var chunks []prdChunk
+	var current prdChunk
+	flush := func() {
+		if strings.TrimSpace(current.Content) != "" {
+			chunks = append(chunks, current)
+		}
+		current = prdChunk{}
+	}
+
+	for _, section := range splitPRDSections(prdContent) {
+		for _, part := range splitLongSection(section, maxChars) {
+			if len(current.Content)+len(part.content) > maxChars {
+				flush()
+			}
+			if current.Content == "" {
+				current.Heading = part.heading
+			}
+			current.Content += part.content
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+// splitPRDSections splits markdown at its headings. Text before the first heading forms a section
+// without heading, and lines inside code fences are never taken as headings.
+func splitPRDSections(prdContent string) []prdSection {
+	var sections []prdSection
+	var current prdSection
+	inFence := false
+
+	for _, line := range strings.SplitAfter(prdContent, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+
+		if !inFence && isMarkdownHeading(trimmed) {
+			if current.content != "" {
+				sections = append(sections, current)
+			}
+			current = prdSection{heading: strings.TrimSpace(strings.TrimLeft(trimmed, "#"))}
+		}
+		current.content += line
+	}
+	if current.content != "" {
+		sections = append(sections, current)
+	}
+
+	return sections
+}
+
+// isMarkdownHeading checks if a line is an ATX heading such as "## Features"
+func isMarkdownHeading(line string) bool {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	return level >= 1 && level <= 6 && len(line) > level && line[level] == ' '
+}
+
+// splitLongSection splits a section longer than maxChars at blank lines. Parts after the first
+// repeat the heading so the model knows where they belong.
+func splitLongSection(section prdSection, maxChars int) []prdSection {
+	if len(section.content) <= maxChars {
+		return []prdSection{section}
+	}
+
+	var parts []prdSection
+	var current string
+	for _, paragraph := range strings.SplitAfter(section.content, "\n\n") {
+		if current != "" && len(current)+len(paragraph) > maxChars {
+			parts = append(parts, prdSection{heading: section.heading, content: current})
+			current = ""
+			if section.heading != "" {
+				current = fmt.Sprintf("(continued: %s)\n\n", section.heading)
+			}
+		}
+		current += paragraph
+	}
+	if current != "" {
+		parts = append(parts, prdSection{heading: section.heading, content: current})
+	}
+
+	return parts
+}
+
+// generateTasksInChunks generates tasks for a PRD, splitting it by section when it is longer than
+// options.MaxChunkChars. The parts are generated in parallel, their tasks are merged with globally
+// unique IDs, and the model is then asked for dependencies between tasks of different parts.
+func generateTasksInChunks(generator llm.TaskGenerator, prdContent string, options TaskGenerationOptions, report func(progress TaskGenerationProgress)) TaskGenerationResult {
+	maxChars := options.MaxChunkChars
+	if maxChars <= 0 {
+		maxChars = defaultPRDChunkChars
+	}
+
+	chunks := splitPRD(prdContent, maxChars)
+	if len(chunks) <= 1 {
+		return generateTasks(generator, prdContent, options)
+	}
+
+	outline := prdOutline(chunks)
+	for i, chunk := range chunks {
+		report(TaskGenerationProgress{Chunk: i + 1, Chunks: len(chunks), Section: chunk.Heading, State: ChunkQueued})
+	}
+
+	results := make([]TaskGenerationResult, len(chunks))
+	slots := make(chan struct{}, maxParallelChunks)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk prdChunk) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			progress := TaskGenerationProgress{Chunk: i + 1, Chunks: len(chunks), Section: chunk.Heading, State: ChunkStarted}
+			report(progress)
+
+			userMessage := fmt.Sprintf("The PRD is too long to handle at once, so it was split into %d parts. Outline of the whole PRD:\n%s\n\nGenerate implementation tasks ONLY for part %d below (aim for 3-15 tasks). Number its tasks from 1 and only reference tasks of this part in dependencies; dependencies on other parts are resolved later.\n\n%s%s", len(chunks), outline, i+1, chunk.Content, repositoryPrompt(options.Repository))
+			results[i] = generateTaskList(generator, userMessage, options)
+
+			progress.State = ChunkSucceeded
+			progress.TaskCount = len(results[i].Tasks)
+			progress.Message = results[i].Message
+			if !results[i].Success {
+				progress.State = ChunkFailed
+			}
+			report(progress)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	// Merge the parts, renumbering their tasks so that IDs are unique across the PRD
+	var tasks []Task
+	chunkOf := make(map[int]int) // Global task ID to the index of its part
+	repairAttempts := 0
+	for i, result := range results {
+		repairAttempts += result.RepairAttempts
+		if !result.Success {
+			return TaskGenerationResult{
+				Success:        false,
+				Message:        fmt.Sprintf("Failed to generate tasks for part %d (%s): %s", i+1, chunks[i].Heading, result.Message),
+				Tasks:          result.Tasks,
+				Diagnostics:    result.Diagnostics,
+				RepairAttempts: repairAttempts,
+			}
+		}
+
+		globalIDs := make(map[int]int)
+		for _, task := range result.Tasks {
+			globalIDs[task.ID] = len(tasks) + len(globalIDs) + 1
+		}
+		for _, task := range result.Tasks {
+			task.ID = globalIDs[task.ID]
+			dependencies := make([]int, 0, len(task.Dependencies))
+			for _, depID := range task.Dependencies {
+				dependencies = append(dependencies, globalIDs[depID])
+			}
+			task.Dependencies = dependencies
+			chunkOf[task.ID] = i
+			tasks = append(tasks, task)
+		}
+	}
+
+	report(TaskGenerationProgress{Chunks: len(chunks), State: ChunkLinking})
+	message := fmt.Sprintf("Successfully generated %d tasks from %d parts of the PRD", len(tasks), len(chunks))
+	added, err := linkTaskChunks(generator, tasks, chunkOf)
+	if err != nil {
+		message += fmt.Sprintf(" (dependencies between parts could not be resolved: %v)", err)
+	} else if added > 0 {
+		message += fmt.Sprintf(" with %d dependencies between parts", added)
+	}
+
+	if diagnostics := validateTaskGraph(tasks); len(diagnostics) > 0 {
+		return TaskGenerationResult{
+			Success:        false,
+			Message:        fmt.Sprintf("Generated tasks have an invalid dependency graph:\n%s", formatDiagnostics(diagnostics)),
+			Tasks:          tasks,
+			Diagnostics:    diagnostics,
+			RepairAttempts: repairAttempts,
+		}
+	}
+
+	return TaskGenerationResult{
+		Success:        true,
+		Message:        message,
+		Tasks:          tasks,
+		RepairAttempts: repairAttempts,
+	}
+}
+
+// prdOutline lists the part each section heading belongs to
+func prdOutline(chunks []prdChunk) string {
+	var outline strings.Builder
+	for i, chunk := range chunks {
+		var headings []string
+		for _, section := range splitPRDSections(chunk.Content) {
+			if section.heading != "" {
+				headings = append(headings, section.heading)
+			}
+		}
+		if len(headings) == 0 {
+			headings = []string{"(introduction)"}
+		}
+		fmt.Fprintf(&outline, "- Part %d: %s\n", i+1, strings.Join(headings, "; "))
+	}
+	return outline.String()
+}
+
+// linkTaskChunks asks the model which tasks depend on tasks of other parts and adds those
+// dependencies in place. Links within a part, to unknown tasks or that would create a cycle are
+// ignored. It returns the number of dependencies added.
+func linkTaskChunks(generator llm.TaskGenerator, tasks []Task, chunkOf map[int]int) (int, error) {
+	var list strings.Builder
+	for _, task := range tasks {
+		fmt.Fprintf(&list, "%d [part %d] %s: %s\n", task.ID, chunkOf[task.ID]+1, task.Title, task.Description)
+	}
+
+	response, err := generator.Complete(context.Background(), llm.Request{
+		Messages: []llm.Message{
+			{
+				Role:    llm.RoleSystem,
+				Content: "You are an expert project manager. Tasks were generated separately for each part of a PRD, so dependencies between parts are missing. Identify them.",
+			},
+			{
+				Role:    llm.RoleUser,
+				Content: fmt.Sprintf("Tasks (id [part] title: description):\n%s\nFor every task that needs tasks of OTHER parts to be completed first, return a link with its taskId and the IDs it depends on. Do not repeat dependencies within a part and do not create cycles. Return ONLY a JSON object with a \"links\" array.", list.String()),
+			},
+		},
+		MaxTokens:   taskGenerationMaxTokens,
+		Temperature: 0.1,
+		Schema:      taskLinksSchema,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to call %s API: %v", generator.Name(), err)
+	}
+
+	links, err := parseTaskLinks(response)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse JSON response: %v", err)
+	}
+
+	index := make(map[int]int)
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+
+	added := 0
+	for _, link := range links {
+		taskIndex, ok := index[link.TaskID]
+		if !ok {
+			continue
+		}
+		for _, depID := range link.DependsOn {
+			if _, ok := index[depID]; !ok || chunkOf[depID] == chunkOf[link.TaskID] || containsTaskID(tasks[taskIndex].Dependencies, depID) {
+				continue
+			}
+			if dependsOnTask(tasks, index, depID, link.TaskID) {
+				continue
+			}
+			tasks[taskIndex].Dependencies = append(tasks[taskIndex].Dependencies, depID)
+			added++
+		}
+	}
+
+	return added, nil
+}
+
+// dependsOnTask checks if a task transitively depends on target
+func dependsOnTask(tasks []Task, index map[int]int, taskID, target int) bool {
+	visited := make(map[int]bool)
+	stack := []int{taskID}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == target {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		stack = append(stack, tasks[index[current]].Dependencies...)
+	}
+	return false
+}
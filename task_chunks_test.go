@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"specprint/pkg/llm"
+)
+
+func TestSplitPRDBySection(t *testing.T) {
+	prd := "# Shop\n\n" + strings.Repeat("Intro text. ", 10) + "\n\n## Checkout\n\n" + strings.Repeat("Pay with card. ", 10) + "\n\n```\n# not a heading\n```\n\n## Search\n\nFind products.\n"
+
+	chunks := splitPRD(prd, 230)
+	var headings []string
+	for _, chunk := range chunks {
+		headings = append(headings, chunk.Heading)
+	}
+	if !reflect.DeepEqual(headings, []string{"Shop", "Checkout"}) {
+		t.Fatalf("Expected chunks to start at Shop and Checkout, got %v", headings)
+	}
+	if !strings.Contains(chunks[1].Content, "# not a heading") || !strings.Contains(chunks[1].Content, "## Search") {
+		t.Errorf("Expected fenced lines to stay in their section and Search to join Checkout, got %q", chunks[1].Content)
+	}
+	if strings.Join([]string{chunks[0].Content, chunks[1].Content}, "") != prd {
+		t.Error("Expected chunks to cover the PRD exactly")
+	}
+
+	if chunks := splitPRD(prd, 10000); len(chunks) != 1 {
+		t.Errorf("Expected a short PRD to stay in one chunk, got %d", len(chunks))
+	}
+}
+
+func TestSplitPRDSplitsLongSections(t *testing.T) {
+	prd := "## Reports\n\n" + strings.Repeat("A paragraph about reports.\n\n", 20)
+
+	chunks := splitPRD(prd, 150)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected the section to be split, got %d chunks", len(chunks))
+	}
+	for _, chunk := range chunks[1:] {
+		if chunk.Heading != "Reports" || !strings.HasPrefix(chunk.Content, "(continued: Reports)") {
+			t.Errorf("Expected continued parts to name their section, got %q", chunk.Content)
+		}
+	}
+}
+
+// chunkGenerator answers part prompts with two tasks per part and the linking prompt with links
+type chunkGenerator struct {
+	mu    sync.Mutex
+	links string
+	parts int
+}
+
+func (g *chunkGenerator) Name() string {
+	return "Chunked"
+}
+
+func (g *chunkGenerator) Complete(ctx context.Context, req llm.Request) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	prompt := req.Messages[len(req.Messages)-1].Content
+	if req.Schema == taskLinksSchema {
+		return g.links, nil
+	}
+
+	g.parts++
+	part := prompt[strings.LastIndex(prompt, "## ")+3:]
+	part = strings.TrimSpace(part[:strings.Index(part, "\n")])
+	return fmt.Sprintf(`{"tasks":[
+		{"id":1,"title":"Design %[1]s","description":"Design it","dependencies":[],"priority":"high","estimate":"2h"},
+		{"id":2,"title":"Build %[1]s","description":"Build it","dependencies":[1],"priority":"high","estimate":"1d"}]}`, part), nil
+}
+
+func TestGenerateTasksInChunksMergesParts(t *testing.T) {
+	prd := "## Accounts\n\n" + strings.Repeat("Users sign up. ", 10) + "\n\n## Billing\n\n" + strings.Repeat("Users pay. ", 10) + "\n"
+	// Only 4 -> 2 is kept: 3 is in the same part, 99 does not exist and 1 -> 4 would be a cycle
+	generator := &chunkGenerator{links: `{"links":[{"taskId":4,"dependsOn":[2,3,99]},{"taskId":1,"dependsOn":[4]}]}`}
+
+	var mu sync.Mutex
+	var states []string
+	result := generateTasksInChunks(generator, prd, TaskGenerationOptions{MaxChunkChars: 200}, func(progress TaskGenerationProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		states = append(states, progress.State)
+	})
+	if !result.Success {
+		t.Fatalf("Expected success, got: %s", result.Message)
+	}
+	if generator.parts != 2 {
+		t.Errorf("Expected one request per part, got %d", generator.parts)
+	}
+
+	var titles []string
+	for _, task := range result.Tasks {
+		titles = append(titles, fmt.Sprintf("%d %s %v", task.ID, task.Title, task.Dependencies))
+	}
+	expected := []string{"1 Design Accounts []", "2 Build Accounts [1]", "3 Design Billing []", "4 Build Billing [3 2]"}
+	if !reflect.DeepEqual(titles, expected) {
+		t.Errorf("Expected %v, got %v", expected, titles)
+	}
+
+	if len(states) != 7 || states[len(states)-1] != ChunkLinking {
+		t.Errorf("Expected queued, started and finished per part, then linking; got %v", states)
+	}
+}
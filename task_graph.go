@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of problems validateTaskGraph can report
+const (
+	DiagnosticDuplicateID       = "duplicate-id"
+	DiagnosticMissingDependency = "missing-dependency"
+	DiagnosticSelfDependency    = "self-dependency"
+	DiagnosticCycle             = "cycle"
+)
+
+// TaskDiagnostic describes a problem with one task in a task graph
+type TaskDiagnostic struct {
+	TaskID  int    `json:"taskId"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Related []int  `json:"related,omitempty"` // Other task IDs involved, e.g. the members of a cycle
+}
+
+// validateTaskGraph checks that task IDs are unique, that every dependency refers to an
+// existing task other than the task itself, and that the dependency graph is acyclic
+func validateTaskGraph(tasks []Task) []TaskDiagnostic {
+	var diagnostics []TaskDiagnostic
+
+	// Index tasks by ID, keeping the first occurrence of duplicated IDs
+	byID := make(map[int]Task)
+	var ids []int
+	for _, task := range tasks {
+		if _, exists := byID[task.ID]; exists {
+			diagnostics = append(diagnostics, TaskDiagnostic{
+				TaskID:  task.ID,
+				Kind:    DiagnosticDuplicateID,
+				Message: fmt.Sprintf("Task ID %d is used more than once (\"%s\")", task.ID, task.Title),
+			})
+			continue
+		}
+		byID[task.ID] = task
+		ids = append(ids, task.ID)
+	}
+
+	// Collect the edges that can take part in a cycle
+	edges := make(map[int][]int)
+	for _, id := range ids {
+		for _, depID := range byID[id].Dependencies {
+			switch {
+			case depID == id:
+				diagnostics = append(diagnostics, TaskDiagnostic{
+					TaskID:  id,
+					Kind:    DiagnosticSelfDependency,
+					Message: fmt.Sprintf("Task %d depends on itself", id),
+				})
+			case !containsTaskID(ids, depID):
+				diagnostics = append(diagnostics, TaskDiagnostic{
+					TaskID:  id,
+					Kind:    DiagnosticMissingDependency,
+					Message: fmt.Sprintf("Task %d depends on task %d, which does not exist", id, depID),
+					Related: []int{depID},
+				})
+			default:
+				edges[id] = append(edges[id], depID)
+			}
+		}
+	}
+
+	for _, cycle := range findDependencyCycles(ids, edges) {
+		path := make([]string, 0, len(cycle)+1)
+		for _, id := range cycle {
+			path = append(path, fmt.Sprintf("%d", id))
+		}
+		path = append(path, fmt.Sprintf("%d", cycle[0]))
+		diagnostics = append(diagnostics, TaskDiagnostic{
+			TaskID:  cycle[0],
+			Kind:    DiagnosticCycle,
+			Message: fmt.Sprintf("Dependency cycle: %s", strings.Join(path, " -> ")),
+			Related: cycle,
+		})
+	}
+
+	return diagnostics
+}
+
+// findDependencyCycles returns each cycle in the dependency graph once, as the list of task
+// IDs along the cycle starting at its lowest ID
+func findDependencyCycles(ids []int, edges map[int][]int) [][]int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	sortedIDs := append([]int(nil), ids...)
+	sort.Ints(sortedIDs)
+
+	state := make(map[int]int)
+	var stack []int
+	var cycles [][]int
+	seen := make(map[string]bool)
+
+	var visit func(id int)
+	visit = func(id int) {
+		state[id] = visiting
+		stack = append(stack, id)
+
+		for _, depID := range edges[id] {
+			switch state[depID] {
+			case unvisited:
+				visit(depID)
+			case visiting:
+				// Back edge: the cycle is the part of the stack starting at depID
+				start := len(stack) - 1
+				for stack[start] != depID {
+					start--
+				}
+				cycle := rotateToLowest(append([]int(nil), stack[start:]...))
+				key := fmt.Sprint(cycle)
+				if !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+	}
+
+	for _, id := range sortedIDs {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+
+	return cycles
+}
+
+// rotateToLowest rotates a cycle so that it starts at its lowest task ID
+func rotateToLowest(cycle []int) []int {
+	lowest := 0
+	for i, id := range cycle {
+		if id < cycle[lowest] {
+			lowest = i
+		}
+	}
+	return append(cycle[lowest:], cycle[:lowest]...)
+}
+
+// containsTaskID checks if a task ID is in the list
+func containsTaskID(ids []int, taskID int) bool {
+	for _, id := range ids {
+		if id == taskID {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDiagnostics renders diagnostics as a bullet list for messages and repair prompts
+func formatDiagnostics(diagnostics []TaskDiagnostic) string {
+	lines := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		lines = append(lines, fmt.Sprintf("- [%s] %s", diagnostic.Kind, diagnostic.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// topologicalTaskOrder returns the task IDs ordered so that every task comes after its
+// dependencies. Tasks that become ready at the same time keep their board order.
+func topologicalTaskOrder(tasks []Task) ([]int, error) {
+	if diagnostics := validateTaskGraph(tasks); len(diagnostics) > 0 {
+		return nil, fmt.Errorf("invalid task graph:\n%s", formatDiagnostics(diagnostics))
+	}
+
+	remaining := make(map[int]int)
+	dependents := make(map[int][]int)
+	for _, task := range tasks {
+		remaining[task.ID] = len(task.Dependencies)
+		for _, depID := range task.Dependencies {
+			dependents[depID] = append(dependents[depID], task.ID)
+		}
+	}
+
+	var queue []int
+	for _, task := range tasks {
+		if remaining[task.ID] == 0 {
+			queue = append(queue, task.ID)
+		}
+	}
+
+	order := make([]int, 0, len(tasks))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		order = append(order, id)
+		for _, dependentID := range dependents[id] {
+			remaining[dependentID]--
+			if remaining[dependentID] == 0 {
+				queue = append(queue, dependentID)
+			}
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateTaskGraphValid(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Title: "Set up repository", Dependencies: []int{}},
+		{ID: 2, Title: "Design schema", Dependencies: []int{1}},
+		{ID: 3, Title: "Build API", Dependencies: []int{1, 2}},
+	}
+
+	if diagnostics := validateTaskGraph(tasks); len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %+v", diagnostics)
+	}
+}
+
+func TestValidateTaskGraphReportsProblems(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Title: "Set up repository", Dependencies: []int{}},
+		{ID: 2, Title: "Design schema", Dependencies: []int{2}},
+		{ID: 2, Title: "Design API", Dependencies: []int{}},
+		{ID: 3, Title: "Build API", Dependencies: []int{7}},
+		{ID: 4, Title: "Build UI", Dependencies: []int{6}},
+		{ID: 5, Title: "Write tests", Dependencies: []int{4}},
+		{ID: 6, Title: "Deploy", Dependencies: []int{5}},
+	}
+
+	diagnostics := validateTaskGraph(tasks)
+
+	byKind := make(map[string][]TaskDiagnostic)
+	for _, diagnostic := range diagnostics {
+		byKind[diagnostic.Kind] = append(byKind[diagnostic.Kind], diagnostic)
+	}
+
+	if len(byKind[DiagnosticDuplicateID]) != 1 || byKind[DiagnosticDuplicateID][0].TaskID != 2 {
+		t.Errorf("Expected duplicate ID 2 to be reported, got %+v", byKind[DiagnosticDuplicateID])
+	}
+	if len(byKind[DiagnosticSelfDependency]) != 1 || byKind[DiagnosticSelfDependency][0].TaskID != 2 {
+		t.Errorf("Expected self-dependency of task 2 to be reported, got %+v", byKind[DiagnosticSelfDependency])
+	}
+	if len(byKind[DiagnosticMissingDependency]) != 1 || byKind[DiagnosticMissingDependency][0].TaskID != 3 {
+		t.Errorf("Expected missing dependency of task 3 to be reported, got %+v", byKind[DiagnosticMissingDependency])
+	}
+	if len(byKind[DiagnosticCycle]) != 1 {
+		t.Fatalf("Expected one cycle to be reported, got %+v", byKind[DiagnosticCycle])
+	}
+	if cycle := byKind[DiagnosticCycle][0]; !reflect.DeepEqual(cycle.Related, []int{4, 6, 5}) {
+		t.Errorf("Expected cycle 4 -> 6 -> 5, got %v (%s)", cycle.Related, cycle.Message)
+	}
+}
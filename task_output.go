@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"specprint/pkg/llm"
+)
+
+// generatedTaskSchema is the JSON schema of a single generated task
+const generatedTaskSchema = `{
+  "type": "object",
+  "properties": {
+    "id": {"type": "integer"},
+    "title": {"type": "string"},
+    "description": {"type": "string"},
+    "dependencies": {"type": "array", "items": {"type": "integer"}},
+    "priority": {"type": "string", "enum": ["high", "medium", "low"]},
+    "estimate": {"type": "string"},
+    "acceptanceCriteria": {"type": "array", "items": {"type": "string"}},
+    "files": {"type": "array", "items": {"type": "string"}},
+    "testPlan": {"type": "string"}
+  },
+  "required": ["id", "title", "description", "dependencies", "priority", "estimate", "acceptanceCriteria", "files", "testPlan"],
+  "additionalProperties": false
+}`
+
+// taskListSchema is the JSON schema of the task list generation asks the model for. Providers
+// that support structured output enforce it; the root is an object because OpenAI's strict
+// mode does not accept a top-level array.
+var taskListSchema = &llm.Schema{
+	Name:        "task_list",
+	Description: "Implementation tasks generated from a PRD",
+	Schema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "tasks": {"type": "array", "items": ` + generatedTaskSchema + `}
+  },
+  "required": ["tasks"],
+  "additionalProperties": false
+}`),
+}
+
+// taskRegenerationSchema is the JSON schema of the board changes regeneration asks the model
+// for after the PRD changed
+var taskRegenerationSchema = &llm.Schema{
+	Name:        "task_regeneration",
+	Description: "Tasks to add, change and remove after a PRD changed",
+	Schema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "added": {"type": "array", "items": ` + generatedTaskSchema + `},
+    "changed": {"type": "array", "items": ` + generatedTaskSchema + `},
+    "obsolete": {"type": "array", "items": {"type": "integer"}}
+  },
+  "required": ["added", "changed", "obsolete"],
+  "additionalProperties": false
+}`),
+}
+
+// taskLinksSchema is the JSON schema of the cross-section dependencies that chunked generation
+// asks the model for after merging the tasks of all sections
+var taskLinksSchema = &llm.Schema{
+	Name:        "task_links",
+	Description: "Dependencies between tasks generated from different PRD sections",
+	Schema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "links": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "taskId": {"type": "integer"},
+          "dependsOn": {"type": "array", "items": {"type": "integer"}}
+        },
+        "required": ["taskId", "dependsOn"],
+        "additionalProperties": false
+      }
+    }
+  },
+  "required": ["links"],
+  "additionalProperties": false
+}`),
+}
+
+// taskLink adds dependencies to a task
+type taskLink struct {
+	TaskID    int   `json:"taskId"`
+	DependsOn []int `json:"dependsOn"`
+}
+
+// parseGeneratedTasks extracts the task list from a model response. It accepts a bare JSON
+// array as well as an object with a "tasks" array.
+func parseGeneratedTasks(content string) ([]Task, error) {
+	var tasks []Task
+	err := decodeEmbeddedJSON(content, func(raw json.RawMessage) error {
+		return decodeJSONList(raw, "tasks", &tasks)
+	})
+	return tasks, err
+}
+
+// parseTaskLinks extracts cross-section dependencies from a model response. It accepts a bare
+// JSON array as well as an object with a "links" array.
+func parseTaskLinks(content string) ([]taskLink, error) {
+	var links []taskLink
+	err := decodeEmbeddedJSON(content, func(raw json.RawMessage) error {
+		return decodeJSONList(raw, "links", &links)
+	})
+	return links, err
+}
+
+// decodeEmbeddedJSON finds the JSON value in a model response that decode accepts. Code fences
+// and prose around the JSON are skipped by trying every bracket that starts a well-formed value.
+func decodeEmbeddedJSON(content string, decode func(raw json.RawMessage) error) error {
+	var firstErr error
+	for start := 0; start < len(content); start++ {
+		if content[start] != '[' && content[start] != '{' {
+			continue
+		}
+
+		// Decode the value at start, ignoring anything after it
+		decoder := json.NewDecoder(strings.NewReader(content[start:]))
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == nil {
+			if err = decode(raw); err == nil {
+				return nil
+			}
+			// Brackets inside a well-formed value that decode rejected are not candidates
+			start += int(decoder.InputOffset()) - 1
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if firstErr == nil {
+		return fmt.Errorf("response contains no JSON")
+	}
+	return firstErr
+}
+
+// decodeJSONList decodes either a JSON array or an object holding the array under key into list
+func decodeJSONList(raw json.RawMessage, key string, list interface{}) error {
+	if raw[0] == '[' {
+		return json.Unmarshal(raw, list)
+	}
+
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return err
+	}
+	value, ok := wrapper[key]
+	if !ok || len(value) == 0 || value[0] != '[' {
+		return fmt.Errorf("JSON object has no %q array", key)
+	}
+	return json.Unmarshal(value, list)
+}
@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestParseGeneratedTasksToleratesWrapping(t *testing.T) {
+	responses := map[string]string{
+		"bare array":   `[{"id":1,"title":"Set up","dependencies":[]}]`,
+		"object":       `{"tasks":[{"id":1,"title":"Set up","dependencies":[]}]}`,
+		"code fence":   "```json\n[{\"id\":1,\"title\":\"Set up\",\"dependencies\":[]}]\n```",
+		"prose around": "Here are the tasks [1 total]:\n{\"tasks\":[{\"id\":1,\"title\":\"Set up\",\"dependencies\":[]}]}\nLet me know if you need more.",
+	}
+
+	for name, response := range responses {
+		tasks, err := parseGeneratedTasks(response)
+		if err != nil {
+			t.Errorf("%s: expected tasks, got: %v", name, err)
+			continue
+		}
+		if len(tasks) != 1 || tasks[0].ID != 1 || tasks[0].Title != "Set up" {
+			t.Errorf("%s: expected task 1, got %+v", name, tasks)
+		}
+	}
+}
+
+func TestParseGeneratedTasksRejectsInvalidJSON(t *testing.T) {
+	for _, response := range []string{"", "No tasks today.", `[{"id":1,"title":`, `{"items":[]}`} {
+		if tasks, err := parseGeneratedTasks(response); err == nil {
+			t.Errorf("Expected %q to be rejected, got %+v", response, tasks)
+		}
+	}
+}
+
+func TestTaskBriefUsesStoredCriteria(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+	tasks, err := parseGeneratedTasks(`{"tasks":[{"id":1,"title":"Add login","description":"Login endpoint","dependencies":[],"priority":"high","estimate":"1d",
+		"acceptanceCriteria":["Valid credentials return a token"],"files":["api/auth.go"],"testPlan":"Run the auth tests"}]}`)
+	if err != nil {
+		t.Fatalf("Failed to parse tasks: %v", err)
+	}
+	if saved := app.SaveTasks("demo", tasks); !saved.Success {
+		t.Fatalf("Failed to save tasks: %s", saved.Message)
+	}
+
+	brief := app.taskBrief("demo", 1, "Add login (edited)", "Login endpoint")
+	if brief.Title != "Add login (edited)" || len(brief.AcceptanceCriteria) != 1 || brief.Files[0] != "api/auth.go" || brief.TestPlan != "Run the auth tests" {
+		t.Errorf("Expected the stored criteria with the given title, got %+v", brief)
+	}
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"specprint/pkg/llm"
+)
+
+// TaskRegenerationPlan describes how a task board should change after its PRD changed. Tasks
+// that are neither changed nor obsolete keep their ID, status, branch and sessions.
+type TaskRegenerationPlan struct {
+	Added    []Task `json:"added"`    // New tasks, with IDs above the highest ID on the board
+	Changed  []Task `json:"changed"`  // Existing tasks with new fields; their state is kept
+	Obsolete []int  `json:"obsolete"` // IDs of tasks the PRD no longer calls for
+	PRDHash  string `json:"prdHash"`  // Identifies the PRD version the plan was made for
+	Spec     string `json:"spec"`     // The spec document whose tasks the plan updates; empty for PRD.md
+}
+
+// TaskRegenerationResult represents the result of proposing board changes for a changed PRD
+type TaskRegenerationResult struct {
+	Success        bool                  `json:"success"`
+	Message        string                `json:"message"`
+	Plan           *TaskRegenerationPlan `json:"plan,omitempty"`
+	Diff           string                `json:"diff,omitempty"` // What changed in the PRD since the tasks were generated
+	Diagnostics    []TaskDiagnostic      `json:"diagnostics,omitempty"`
+	RepairAttempts int                   `json:"repairAttempts,omitempty"`
+}
+
+// taskRegenerationSystemPrompt instructs the model to update an existing task board
+const taskRegenerationSystemPrompt = `You are an expert project manager and software architect. A Product Requirements Document (PRD) changed after implementation tasks were generated from it, and some tasks are already in progress. Update the task list to match the new PRD with as few changes as possible.
+
+Return a JSON object with:
+- added: new tasks the changed PRD requires. Give them IDs above the highest existing ID.
+- changed: existing tasks whose title, description, dependencies, priority, estimate, acceptance criteria, files or test plan must change. Keep their IDs and return all of their fields.
+- obsolete: IDs of existing tasks the PRD no longer calls for.
+
+Each task has id, title (max 80 characters), description (max 200 characters), dependencies (array of task IDs), priority ("high", "medium" or "low"), estimate (like "2h", "1d"), acceptanceCriteria (checkable conditions for the task to be done), files (paths likely to change) and testPlan (how to verify it). Leave tasks that are unaffected by the change out of all three lists. Dependencies may refer to existing and added tasks but not to obsolete ones, and must not form cycles.
+
+Return ONLY the JSON object. Do not include any other text or formatting.`
+
+// ProposeTaskRegeneration compares a workspace's PRD with the version its tasks were generated
+// from and asks the model which tasks to add, change and remove. The board is not modified;
+// pass the plan to ApplyTaskRegeneration to apply it.
+func (a *App) ProposeTaskRegeneration(workspaceName string) TaskRegenerationResult {
+	return a.ProposeSpecTaskRegeneration(workspaceName, DefaultSpecID)
+}
+
+// ProposeSpecTaskRegeneration works like ProposeTaskRegeneration for the tasks of one spec
+// document
+func (a *App) ProposeSpecTaskRegeneration(workspaceName, specID string) TaskRegenerationResult {
+	targetWorkspace, err := a.findSpecWorkspace(workspaceName, specID)
+	if err != nil {
+		return TaskRegenerationResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	prdContent, err := os.ReadFile(specPath(targetWorkspace.Path, specID))
+	if os.IsNotExist(err) {
+		return TaskRegenerationResult{
+			Success: false,
+			Message: fmt.Sprintf("Workspace '%s' does not have %s", workspaceName, specLabel(specID)),
+		}
+	}
+	if err != nil {
+		return TaskRegenerationResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to read PRD file: %v", err),
+		}
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return TaskRegenerationResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load tasks: %v", err),
+		}
+	}
+
+	if len(board.specTasks(specID)) == 0 {
+		return TaskRegenerationResult{
+			Success: false,
+			Message: fmt.Sprintf("Workspace '%s' has no tasks for %s yet; generate them first", workspaceName, specLabel(specID)),
+		}
+	}
+
+	hash := prdHash(string(prdContent))
+	source := board.source(specID).Content
+	if source == string(prdContent) {
+		return TaskRegenerationResult{
+			Success: true,
+			Message: "The PRD has not changed since the tasks were generated",
+			Plan:    &TaskRegenerationPlan{Added: []Task{}, Changed: []Task{}, Obsolete: []int{}, PRDHash: hash, Spec: specID},
+		}
+	}
+
+	generator, err := a.taskGenerator(workspaceName)
+	if err != nil {
+		return TaskRegenerationResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	diff := formatDiff(diffLines(source, string(prdContent)), 3)
+	options := TaskGenerationOptions{RepairAttempts: 1, Repository: a.workspaceRepositorySummary(workspaceName)}
+	result := proposeTaskRegeneration(generator, board, specID, string(prdContent), diff, options)
+	result.Diff = diff
+	if result.Plan != nil {
+		result.Plan.PRDHash = hash
+	}
+	return result
+}
+
+// proposeTaskRegeneration asks the generator for a plan that updates a spec's tasks to the new
+// spec content and validates it against the board
+func proposeTaskRegeneration(generator llm.TaskGenerator, board TaskBoard, specID, prdContent, diff string, options TaskGenerationOptions) TaskRegenerationResult {
+	type boardTask struct {
+		ID           int        `json:"id"`
+		Title        string     `json:"title"`
+		Description  string     `json:"description"`
+		Dependencies []int      `json:"dependencies"`
+		Priority     string     `json:"priority"`
+		Estimate     string     `json:"estimate"`
+		Criteria     []string   `json:"acceptanceCriteria,omitempty"`
+		Files        []string   `json:"files,omitempty"`
+		TestPlan     string     `json:"testPlan,omitempty"`
+		Status       TaskStatus `json:"status"`
+	}
+	specTasks := board.specTasks(specID)
+	current := make([]boardTask, 0, len(specTasks))
+	for _, task := range specTasks {
+		current = append(current, boardTask{task.ID, task.Title, task.Description, task.Dependencies, task.Priority, task.Estimate, task.AcceptanceCriteria, task.Files, task.TestPlan, task.Status})
+	}
+	currentJSON, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return TaskRegenerationResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to encode tasks: %v", err),
+		}
+	}
+
+	var change string
+	if board.source(specID).Content == "" {
+		// Boards generated before the PRD was recorded have no baseline to diff against
+		change = fmt.Sprintf("The PRD version the tasks were generated from is unknown. The current PRD is:\n\n%s", prdContent)
+	} else {
+		change = fmt.Sprintf("The PRD changed as follows (unified diff, + added, - removed):\n\n%s\nThe complete new PRD is:\n\n%s", diff, prdContent)
+	}
+
+	messages := []llm.Message{
+		{
+			Role:    llm.RoleSystem,
+			Content: taskRegenerationSystemPrompt,
+		},
+		{
+			Role:    llm.RoleUser,
+			Content: fmt.Sprintf("Current tasks (the highest ID is %d):\n%s\n\n%s%s", board.nextTaskID()-1, currentJSON, change, repositoryPrompt(options.Repository)),
+		},
+	}
+
+	attempt := 0
+	for {
+		responseContent, err := generator.Complete(context.Background(), llm.Request{
+			Messages:    messages,
+			MaxTokens:   taskGenerationMaxTokens,
+			Temperature: 0.1,
+			Schema:      taskRegenerationSchema,
+		})
+		if err != nil {
+			return TaskRegenerationResult{
+				Success: false,
+				Message: fmt.Sprintf("Failed to call %s API: %v", generator.Name(), err),
+			}
+		}
+
+		var plan TaskRegenerationPlan
+		var diagnostics []TaskDiagnostic
+		err = decodeEmbeddedJSON(responseContent, func(raw json.RawMessage) error {
+			plan = TaskRegenerationPlan{Spec: specID}
+			if err := json.Unmarshal(raw, &plan); err != nil {
+				return err
+			}
+			if plan.Added == nil && plan.Changed == nil && plan.Obsolete == nil {
+				return fmt.Errorf("JSON object has no \"added\", \"changed\" or \"obsolete\" array")
+			}
+			return nil
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to parse JSON response: %v", err)
+		} else {
+			_, diagnostics, err = applyRegenerationPlan(board.Tasks, plan)
+		}
+
+		if err == nil {
+			normalizeRegenerationPlan(&plan)
+			return TaskRegenerationResult{
+				Success:        true,
+				Message:        fmt.Sprintf("Proposed %d new, %d changed and %d obsolete tasks", len(plan.Added), len(plan.Changed), len(plan.Obsolete)),
+				Plan:           &plan,
+				RepairAttempts: attempt,
+			}
+		}
+		if attempt >= options.RepairAttempts {
+			return TaskRegenerationResult{
+				Success:        false,
+				Message:        fmt.Sprintf("Proposed changes are invalid: %v", err),
+				Diagnostics:    diagnostics,
+				RepairAttempts: attempt,
+			}
+		}
+
+		attempt++
+		messages = append(messages,
+			llm.Message{
+				Role:    llm.RoleAssistant,
+				Content: responseContent,
+			},
+			llm.Message{
+				Role:    llm.RoleUser,
+				Content: fmt.Sprintf("These changes cannot be applied: %v\n\nReturn the complete corrected JSON object with added, changed and obsolete tasks.", err),
+			},
+		)
+	}
+}
+
+// normalizeRegenerationPlan replaces missing lists with empty ones so the frontend can rely on them
+func normalizeRegenerationPlan(plan *TaskRegenerationPlan) {
+	if plan.Added == nil {
+		plan.Added = []Task{}
+	}
+	if plan.Changed == nil {
+		plan.Changed = []Task{}
+	}
+	if plan.Obsolete == nil {
+		plan.Obsolete = []int{}
+	}
+}
+
+// applyRegenerationPlan returns the tasks with the plan applied. Only tasks of the plan's spec
+// can be changed or made obsolete. Changed tasks keep their status, branch and sessions; running
+// tasks cannot be made obsolete; dependencies on obsolete tasks are dropped. The resulting
+// dependency graph must be valid.
+func applyRegenerationPlan(tasks []Task, plan TaskRegenerationPlan) ([]Task, []TaskDiagnostic, error) {
+	if len(plan.Added) > 0 {
+		if err := validateGeneratedTasks(plan.Added); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(plan.Changed) > 0 {
+		if err := validateGeneratedTasks(plan.Changed); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	index := make(map[int]int)
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+
+	obsolete := make(map[int]bool)
+	for _, taskID := range plan.Obsolete {
+		i, ok := index[taskID]
+		if !ok || tasks[i].Spec != plan.Spec {
+			return nil, nil, fmt.Errorf("obsolete task %d does not exist", taskID)
+		}
+		if tasks[i].Status == TaskStatusRunning {
+			return nil, nil, fmt.Errorf("task %d is running and cannot be removed", taskID)
+		}
+		obsolete[taskID] = true
+	}
+
+	updated := make([]Task, len(tasks))
+	copy(updated, tasks)
+	changed := make(map[int]bool)
+	for _, change := range plan.Changed {
+		i, ok := index[change.ID]
+		if !ok || tasks[i].Spec != plan.Spec {
+			return nil, nil, fmt.Errorf("changed task %d does not exist", change.ID)
+		}
+		if obsolete[change.ID] || changed[change.ID] {
+			return nil, nil, fmt.Errorf("task %d is changed more than once or both changed and obsolete", change.ID)
+		}
+		changed[change.ID] = true
+
+		task := &updated[i]
+		task.Title = change.Title
+		task.Description = change.Description
+		task.Dependencies = change.Dependencies
+		task.Priority = change.Priority
+		task.Estimate = change.Estimate
+		task.AcceptanceCriteria = change.AcceptanceCriteria
+		task.Files = change.Files
+		task.TestPlan = change.TestPlan
+		if change.PRDVersion > 0 {
+			task.PRDVersion = change.PRDVersion
+		}
+		normalizeTask(task)
+	}
+
+	result := make([]Task, 0, len(updated)+len(plan.Added))
+	for _, task := range updated {
+		if obsolete[task.ID] {
+			continue
+		}
+		dependencies := make([]int, 0, len(task.Dependencies))
+		for _, depID := range task.Dependencies {
+			if !obsolete[depID] {
+				dependencies = append(dependencies, depID)
+			}
+		}
+		task.Dependencies = dependencies
+		result = append(result, task)
+	}
+
+	for _, task := range plan.Added {
+		if _, exists := index[task.ID]; exists {
+			return nil, nil, fmt.Errorf("added task %d reuses the ID of an existing task", task.ID)
+		}
+		task.Status = TaskStatusTodo
+		task.Spec = plan.Spec
+		normalizeTask(&task)
+		result = append(result, task)
+	}
+
+	if diagnostics := validateTaskGraph(result); len(diagnostics) > 0 {
+		return nil, diagnostics, fmt.Errorf("the resulting dependency graph is invalid:\n%s", formatDiagnostics(diagnostics))
+	}
+
+	return result, nil, nil
+}
+
+// ApplyTaskRegeneration applies a plan from ProposeTaskRegeneration to a workspace's board and
+// records the current PRD as the version the tasks were generated from. The plan is rejected if
+// the PRD changed since it was proposed.
+func (a *App) ApplyTaskRegeneration(workspaceName string, plan TaskRegenerationPlan) TaskListResult {
+	targetWorkspace, err := a.findSpecWorkspace(workspaceName, plan.Spec)
+	if err != nil {
+		return TaskListResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	prdContent, err := os.ReadFile(specPath(targetWorkspace.Path, plan.Spec))
+	if err != nil {
+		return TaskListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to read PRD file: %v", err),
+		}
+	}
+
+	if plan.PRDHash != prdHash(string(prdContent)) {
+		return TaskListResult{
+			Success: false,
+			Message: "The PRD changed since the changes were proposed; propose them again",
+		}
+	}
+
+	// Link new and changed tasks to the PRD version they now come from; history is best effort
+	prdVersion, _, err := a.recordCurrentSpec(targetWorkspace, plan.Spec)
+	if err != nil {
+		prdVersion = 0
+	}
+	for i := range plan.Added {
+		plan.Added[i].PRDVersion = prdVersion
+	}
+	for i := range plan.Changed {
+		plan.Changed[i].PRDVersion = prdVersion
+	}
+
+	board, err := a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		tasks, _, err := applyRegenerationPlan(board.Tasks, plan)
+		if err != nil {
+			return err
+		}
+		board.Tasks = tasks
+		board.setSource(plan.Spec, string(prdContent), prdVersion)
+		return nil
+	})
+	if err != nil {
+		return TaskListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to apply task changes: %v", err),
+		}
+	}
+
+	return TaskListResult{
+		Success:     true,
+		Message:     fmt.Sprintf("Added %d, changed %d and removed %d tasks", len(plan.Added), len(plan.Changed), len(plan.Obsolete)),
+		Tasks:       board.Tasks,
+		LastUpdated: board.LastUpdated,
+	}
+}
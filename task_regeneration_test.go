@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyRegenerationPlanPreservesTaskState(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Title: "Set up", Dependencies: []int{}, Status: TaskStatusDone, BranchName: "task-1-set-up"},
+		{ID: 2, Title: "Login", Dependencies: []int{1}, Status: TaskStatusReview, SessionID: "session-2"},
+		{ID: 3, Title: "Search", Dependencies: []int{1}, Status: TaskStatusTodo},
+		{ID: 4, Title: "Export", Dependencies: []int{3}, Status: TaskStatusTodo},
+	}
+	plan := TaskRegenerationPlan{
+		Added:    []Task{{ID: 5, Title: "SSO", Description: "Add SSO", Dependencies: []int{2}, Priority: "high", Estimate: "1d"}},
+		Changed:  []Task{{ID: 2, Title: "Login with SSO", Description: "Support SSO", Dependencies: []int{1}, Priority: "high", Estimate: "2d"}},
+		Obsolete: []int{3},
+	}
+
+	updated, _, err := applyRegenerationPlan(tasks, plan)
+	if err != nil {
+		t.Fatalf("Expected plan to apply, got: %v", err)
+	}
+	if len(updated) != 4 {
+		t.Fatalf("Expected 4 tasks, got %+v", updated)
+	}
+	if updated[0].BranchName != "task-1-set-up" || updated[0].Status != TaskStatusDone {
+		t.Errorf("Expected unchanged task to keep its state, got %+v", updated[0])
+	}
+	if updated[1].Title != "Login with SSO" || updated[1].Status != TaskStatusReview || updated[1].SessionID != "session-2" {
+		t.Errorf("Expected changed task to keep its status and session, got %+v", updated[1])
+	}
+	if updated[2].ID != 4 || len(updated[2].Dependencies) != 0 {
+		t.Errorf("Expected dependency on the obsolete task to be dropped, got %+v", updated[2])
+	}
+	if updated[3].ID != 5 || updated[3].Status != TaskStatusTodo {
+		t.Errorf("Expected added task in todo, got %+v", updated[3])
+	}
+	if len(tasks) != 4 || tasks[1].Title != "Login" {
+		t.Error("Expected the original tasks to be left untouched")
+	}
+}
+
+func TestApplyRegenerationPlanRejectsInvalidPlans(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Title: "Set up", Dependencies: []int{}, Status: TaskStatusRunning},
+		{ID: 2, Title: "Login", Dependencies: []int{1}},
+	}
+	newTask := func(id int, deps ...int) Task {
+		return Task{ID: id, Title: "Task", Description: "Do it", Dependencies: append([]int{}, deps...), Priority: "low", Estimate: "1h"}
+	}
+
+	plans := map[string]TaskRegenerationPlan{
+		"running obsolete": {Obsolete: []int{1}},
+		"unknown changed":  {Changed: []Task{newTask(9)}},
+		"reused ID":        {Added: []Task{newTask(2)}},
+		"cycle":            {Changed: []Task{newTask(1, 2)}},
+	}
+	for name, plan := range plans {
+		if _, _, err := applyRegenerationPlan(tasks, plan); err == nil {
+			t.Errorf("%s: expected plan to be rejected", name)
+		}
+	}
+}
+
+func TestRegenerationProposalAndApply(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+	prdPath := filepath.Join(os.Getenv("HOME"), ".aicodingtool", "repos", "demo", "PRD.md")
+	if err := os.WriteFile(prdPath, []byte("# PRD\nLogin\n"), 0644); err != nil {
+		t.Fatalf("Failed to write PRD: %v", err)
+	}
+	_, err := app.tasks.Update("demo", func(board *TaskBoard) error {
+		board.Tasks = []Task{{ID: 1, Title: "Login", Dependencies: []int{}, Status: TaskStatusDone}}
+		board.SourcePRD = "# PRD\nLogin\n"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to save board: %v", err)
+	}
+
+	if result := app.ProposeTaskRegeneration("demo"); !result.Success || len(result.Plan.Added) != 0 {
+		t.Fatalf("Expected an empty plan for an unchanged PRD, got %+v", result)
+	}
+
+	os.WriteFile(prdPath, []byte("# PRD\nLogin\nSearch\n"), 0644)
+	board, _ := app.tasks.Load("demo")
+	generator := &scriptedGenerator{responses: []string{
+		`{"added":[{"id":2,"title":"Search","description":"Add search","dependencies":[1],"priority":"medium","estimate":"1d"}],"changed":[],"obsolete":[]}`,
+	}}
+	diff := formatDiff(diffLines(board.SourcePRD, "# PRD\nLogin\nSearch\n"), 3)
+	result := proposeTaskRegeneration(generator, board, DefaultSpecID, "# PRD\nLogin\nSearch\n", diff, TaskGenerationOptions{})
+	if !result.Success {
+		t.Fatalf("Expected a plan, got: %s", result.Message)
+	}
+	if prompt := generator.requests[0].Messages[1].Content; !strings.Contains(prompt, "+Search") {
+		t.Errorf("Expected the PRD diff in the prompt, got: %s", prompt)
+	}
+
+	if applied := app.ApplyTaskRegeneration("demo", *result.Plan); applied.Success {
+		t.Error("Expected a plan without the PRD hash to be rejected")
+	}
+	result.Plan.PRDHash = prdHash("# PRD\nLogin\nSearch\n")
+	applied := app.ApplyTaskRegeneration("demo", *result.Plan)
+	if !applied.Success || len(applied.Tasks) != 2 || applied.Tasks[0].Status != TaskStatusDone {
+		t.Fatalf("Expected the new task to be added next to the done one, got %+v", applied)
+	}
+	if board, _ := app.tasks.Load("demo"); board.SourcePRD != "# PRD\nLogin\nSearch\n" {
+		t.Errorf("Expected the applied PRD to become the source PRD, got %q", board.SourcePRD)
+	}
+}
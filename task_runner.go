@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Event emitted to the frontend while the tasks of a workspace are run in parallel
+const (
+	TaskRunnerProgressEvent = "taskrunner:progress"
+	TaskRunnerFinishedEvent = "taskrunner:finished"
+)
+
+// defaultMaxParallelTasks is used when RunWorkspaceTasks is called without a worker count
+const defaultMaxParallelTasks = 2
+
+// States reported in TaskRunProgress
+const (
+	TaskRunQueued    = "queued"
+	TaskRunStarted   = "started"
+	TaskRunSucceeded = "succeeded"
+	TaskRunFailed    = "failed"
+	TaskRunSkipped   = "skipped"
+)
+
+// TaskRunProgress describes a state change of one task during a parallel run
+type TaskRunProgress struct {
+	Workspace string `json:"workspace"`
+	TaskID    int    `json:"taskId"`
+	Title     string `json:"title"`
+	State     string `json:"state"`
+	Message   string `json:"message,omitempty"`
+}
+
+// TaskRunSummary is emitted when a parallel run of a workspace's tasks has finished
+type TaskRunSummary struct {
+	Workspace string `json:"workspace"`
+	Succeeded []int  `json:"succeeded"`
+	Failed    []int  `json:"failed"`
+	Skipped   []int  `json:"skipped"`
+}
+
+// TaskRunnerResult represents the result of starting a parallel run
+type TaskRunnerResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Order   []int  `json:"order,omitempty"` // Task IDs that will be run, in dependency order
+}
+
+// taskScheduler runs tasks in dependency order with a bounded number of concurrent workers.
+// A task starts once all of its dependencies are satisfied; when a task fails, every task
+// that transitively depends on it is skipped.
+type taskScheduler struct {
+	maxParallel int
+	// run executes a single task. ranDependencies are its dependencies that succeeded earlier in
+	// the run, whatever status that left them in.
+	run func(task Task, ranDependencies []int) TaskExecutionResult
+	// satisfied reports whether a task that is not part of the run, or that finished in it,
+	// lets its dependents start (e.g. because its branch was pushed for review)
+	satisfied func(taskID int) bool
+	// report is called for every state change
+	report func(progress TaskRunProgress)
+}
+
+// taskOutcome is what a worker sends back to the scheduler
+type taskOutcome struct {
+	task   Task
+	result TaskExecutionResult
+}
+
+// Run executes the given tasks, which must be in topological order
+func (s *taskScheduler) Run(tasks []Task) TaskRunSummary {
+	maxParallel := s.maxParallel
+	if maxParallel <= 0 {
+		maxParallel = defaultMaxParallelTasks
+	}
+
+	pending := make(map[int]bool)
+	for _, task := range tasks {
+		pending[task.ID] = true
+		s.report(TaskRunProgress{TaskID: task.ID, Title: task.Title, State: TaskRunQueued})
+	}
+
+	var summary TaskRunSummary
+	blocked := make(map[int]bool)   // Failed or skipped in this run
+	succeeded := make(map[int]bool) // Succeeded in this run, even if it changed nothing
+	inFlight := make(map[int]bool)  // Started but not finished yet
+	outcomes := make(chan taskOutcome)
+
+	for {
+		// Start every ready task while workers are free; skip tasks that can never become ready
+		for _, task := range tasks {
+			if !pending[task.ID] || len(inFlight) >= maxParallel {
+				continue
+			}
+
+			ready := true
+			var blockedBy, ranDependencies []int
+			for _, depID := range task.Dependencies {
+				switch {
+				case blocked[depID]:
+					blockedBy = append(blockedBy, depID)
+				case pending[depID], inFlight[depID]:
+					ready = false
+				case succeeded[depID]:
+					ranDependencies = append(ranDependencies, depID)
+				case !s.satisfied(depID):
+					blockedBy = append(blockedBy, depID)
+				}
+			}
+
+			if len(blockedBy) > 0 {
+				delete(pending, task.ID)
+				blocked[task.ID] = true
+				summary.Skipped = append(summary.Skipped, task.ID)
+				s.report(TaskRunProgress{
+					TaskID:  task.ID,
+					Title:   task.Title,
+					State:   TaskRunSkipped,
+					Message: fmt.Sprintf("Skipped because dependencies did not finish: %v", blockedBy),
+				})
+				continue
+			}
+			if !ready {
+				continue
+			}
+
+			delete(pending, task.ID)
+			inFlight[task.ID] = true
+			s.report(TaskRunProgress{TaskID: task.ID, Title: task.Title, State: TaskRunStarted})
+			go func(task Task, ranDependencies []int) {
+				outcomes <- taskOutcome{task: task, result: s.run(task, ranDependencies)}
+			}(task, ranDependencies)
+		}
+
+		if len(inFlight) == 0 {
+			break
+		}
+
+		outcome := <-outcomes
+		delete(inFlight, outcome.task.ID)
+		if outcome.result.Success {
+			succeeded[outcome.task.ID] = true
+			summary.Succeeded = append(summary.Succeeded, outcome.task.ID)
+			s.report(TaskRunProgress{TaskID: outcome.task.ID, Title: outcome.task.Title, State: TaskRunSucceeded, Message: outcome.result.Message})
+		} else {
+			blocked[outcome.task.ID] = true
+			summary.Failed = append(summary.Failed, outcome.task.ID)
+			s.report(TaskRunProgress{TaskID: outcome.task.ID, Title: outcome.task.Title, State: TaskRunFailed, Message: outcome.result.Message})
+		}
+	}
+
+	// Anything still pending waits on a task that never finished (e.g. a dependency cycle)
+	for _, task := range tasks {
+		if pending[task.ID] {
+			summary.Skipped = append(summary.Skipped, task.ID)
+			s.report(TaskRunProgress{TaskID: task.ID, Title: task.Title, State: TaskRunSkipped, Message: "Skipped because its dependencies never became ready"})
+		}
+	}
+
+	return summary
+}
+
+// runnableTasks returns the tasks RunWorkspaceTasks picks up, in the given order: tasks still to
+// do and tasks whose last run failed or was cancelled
+func (b *TaskBoard) runnableTasks(order []int) []Task {
+	var tasks []Task
+	for _, id := range order {
+		task := b.Tasks[b.findTask(id)]
+		switch task.Status {
+		case TaskStatusTodo, TaskStatusFailed, TaskStatusCancelled:
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// RunWorkspaceTasks runs every task of a workspace that is in todo, failed or cancelled state, in
+// dependency order and with up to maxParallel Claude sessions at once. Each task runs in its
+// own worktree, stacked on the branches of the dependencies it waited for. The run happens in
+// the background; progress is reported through the taskrunner:progress and taskrunner:finished events.
+func (a *App) RunWorkspaceTasks(workspaceName, baseBranch string, maxParallel int) TaskRunnerResult {
+	targetWorkspace, err := a.findWorkspace(workspaceName)
+	if err != nil {
+		return TaskRunnerResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	if baseBranch == "" {
+		return TaskRunnerResult{
+			Success: false,
+			Message: "Base branch cannot be empty",
+		}
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return TaskRunnerResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load tasks: %v", err),
+		}
+	}
+
+	order, err := topologicalTaskOrder(board.Tasks)
+	if err != nil {
+		return TaskRunnerResult{
+			Success: false,
+			Message: fmt.Sprintf("Cannot run tasks: %v", err),
+		}
+	}
+
+	tasks := board.runnableTasks(order)
+	var runIDs []int
+	for _, task := range tasks {
+		runIDs = append(runIDs, task.ID)
+	}
+
+	if len(tasks) == 0 {
+		return TaskRunnerResult{
+			Success: true,
+			Message: fmt.Sprintf("No tasks to run in workspace '%s'", workspaceName),
+		}
+	}
+
+	if !a.claimTaskRunner(workspaceName) {
+		return TaskRunnerResult{
+			Success: false,
+			Message: fmt.Sprintf("Tasks of workspace '%s' are already being run", workspaceName),
+		}
+	}
+
+	scheduler := &taskScheduler{
+		maxParallel: maxParallel,
+		run: func(task Task, ranDependencies []int) TaskExecutionResult {
+			return a.executeTask(targetWorkspace, task.ID, task.Title, task.Description, baseBranch, TaskRunOptions{
+				StackOnDependencies:   true,
+				SatisfiedDependencies: ranDependencies,
+			})
+		},
+		satisfied: func(taskID int) bool {
+			current, err := a.tasks.Load(workspaceName)
+			if err != nil {
+				return false
+			}
+			index := current.findTask(taskID)
+			return index >= 0 && (isDependencyFinished(current.Tasks[index].Status) || current.Tasks[index].Status == TaskStatusReview)
+		},
+		report: func(progress TaskRunProgress) {
+			progress.Workspace = workspaceName
+			a.emitEvent(TaskRunnerProgressEvent, progress)
+		},
+	}
+
+	go func() {
+		defer a.releaseTaskRunner(workspaceName)
+
+		summary := scheduler.Run(tasks)
+		summary.Workspace = workspaceName
+		a.emitEvent(TaskRunnerFinishedEvent, summary)
+	}()
+
+	return TaskRunnerResult{
+		Success: true,
+		Message: fmt.Sprintf("Started running %d tasks in workspace '%s'", len(tasks), workspaceName),
+		Order:   runIDs,
+	}
+}
+
+// claimTaskRunner marks a workspace as being run, returning false if it already is
+func (a *App) claimTaskRunner(workspaceName string) bool {
+	a.runnersMu.Lock()
+	defer a.runnersMu.Unlock()
+
+	if a.activeRunners == nil {
+		a.activeRunners = make(map[string]bool)
+	}
+	if a.activeRunners[workspaceName] {
+		return false
+	}
+	a.activeRunners[workspaceName] = true
+	return true
+}
+
+// releaseTaskRunner marks a workspace as no longer being run
+func (a *App) releaseTaskRunner(workspaceName string) {
+	a.runnersMu.Lock()
+	defer a.runnersMu.Unlock()
+
+	delete(a.activeRunners, workspaceName)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTopologicalTaskOrder(t *testing.T) {
+	tasks := []Task{
+		{ID: 3, Title: "Build API", Dependencies: []int{1, 2}},
+		{ID: 1, Title: "Set up repository", Dependencies: []int{}},
+		{ID: 4, Title: "Write docs", Dependencies: []int{}},
+		{ID: 2, Title: "Design schema", Dependencies: []int{1}},
+	}
+
+	order, err := topologicalTaskOrder(tasks)
+	if err != nil {
+		t.Fatalf("Expected a valid order, got %v", err)
+	}
+	if !reflect.DeepEqual(order, []int{1, 4, 2, 3}) {
+		t.Errorf("Expected order [1 4 2 3], got %v", order)
+	}
+
+	tasks = append(tasks, Task{ID: 5, Title: "Deploy", Dependencies: []int{5}})
+	if _, err := topologicalTaskOrder(tasks); err == nil {
+		t.Error("Expected an invalid graph to be rejected")
+	}
+}
+
+func TestRunnableTasks(t *testing.T) {
+	board := TaskBoard{Tasks: []Task{
+		{ID: 1, Title: "Set up", Status: TaskStatusDone},
+		{ID: 2, Title: "Schema", Status: TaskStatusCancelled},
+		{ID: 3, Title: "API", Status: TaskStatusFailed},
+		{ID: 4, Title: "UI", Status: TaskStatusTodo},
+		{ID: 5, Title: "Docs", Status: TaskStatusReview},
+		{ID: 6, Title: "Deploy", Status: TaskStatusRunning},
+	}}
+
+	var ids []int
+	for _, task := range board.runnableTasks([]int{1, 2, 3, 4, 5, 6}) {
+		ids = append(ids, task.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{2, 3, 4}) {
+		t.Errorf("Expected cancelled, failed and todo tasks to run, got %v", ids)
+	}
+}
+
+func TestTaskSchedulerRunsIndependentTasksInParallel(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Title: "Backend", Dependencies: []int{}},
+		{ID: 2, Title: "Frontend", Dependencies: []int{}},
+		{ID: 3, Title: "Docs", Dependencies: []int{}},
+		{ID: 4, Title: "Integration", Dependencies: []int{1, 2}},
+	}
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	finished := make(map[int]bool)
+	var startedBeforeDeps []int
+
+	scheduler := &taskScheduler{
+		maxParallel: 2,
+		run: func(task Task, ranDependencies []int) TaskExecutionResult {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			for _, depID := range task.Dependencies {
+				if !finished[depID] {
+					startedBeforeDeps = append(startedBeforeDeps, task.ID)
+				}
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			finished[task.ID] = true
+			mu.Unlock()
+			return TaskExecutionResult{Success: true}
+		},
+		satisfied: func(taskID int) bool { return true },
+		report:    func(progress TaskRunProgress) {},
+	}
+
+	summary := scheduler.Run(tasks)
+
+	sort.Ints(summary.Succeeded)
+	if !reflect.DeepEqual(summary.Succeeded, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected all tasks to succeed, got %+v", summary)
+	}
+	if peak != 2 {
+		t.Errorf("Expected 2 tasks to run at once, got %d", peak)
+	}
+	if len(startedBeforeDeps) > 0 {
+		t.Errorf("Tasks %v started before their dependencies finished", startedBeforeDeps)
+	}
+}
+
+func TestTaskSchedulerSkipsDependentsOfFailedTasks(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Title: "Backend", Dependencies: []int{}},
+		{ID: 2, Title: "Frontend", Dependencies: []int{}},
+		{ID: 3, Title: "API client", Dependencies: []int{1}},
+		{ID: 4, Title: "Integration", Dependencies: []int{3}},
+		{ID: 5, Title: "Polish UI", Dependencies: []int{2, 9}},
+	}
+
+	var mu sync.Mutex
+	var ran []int
+	var skipped []int
+
+	scheduler := &taskScheduler{
+		maxParallel: 3,
+		run: func(task Task, ranDependencies []int) TaskExecutionResult {
+			mu.Lock()
+			ran = append(ran, task.ID)
+			mu.Unlock()
+			return TaskExecutionResult{Success: task.ID != 1, Message: "done"}
+		},
+		// Task 9 is not part of the run and has not finished
+		satisfied: func(taskID int) bool { return taskID != 9 },
+		report: func(progress TaskRunProgress) {
+			if progress.State == TaskRunSkipped {
+				skipped = append(skipped, progress.TaskID)
+			}
+		},
+	}
+
+	summary := scheduler.Run(tasks)
+
+	sort.Ints(ran)
+	if !reflect.DeepEqual(ran, []int{1, 2}) {
+		t.Errorf("Expected only tasks 1 and 2 to run, got %v", ran)
+	}
+	if !reflect.DeepEqual(summary.Failed, []int{1}) {
+		t.Errorf("Expected task 1 to fail, got %v", summary.Failed)
+	}
+	sort.Ints(summary.Skipped)
+	if !reflect.DeepEqual(summary.Skipped, []int{3, 4, 5}) {
+		t.Errorf("Expected tasks 3, 4 and 5 to be skipped, got %v", summary.Skipped)
+	}
+	if len(skipped) != 3 {
+		t.Errorf("Expected 3 skipped progress events, got %v", skipped)
+	}
+}
+
+func TestTaskSchedulerRunsDependentsOfTasksWithoutChanges(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Title: "Check config", Dependencies: []int{}},
+		{ID: 2, Title: "Use config", Dependencies: []int{1}},
+	}
+
+	var mu sync.Mutex
+	ranDependenciesOf := make(map[int][]int)
+	scheduler := &taskScheduler{
+		maxParallel: 2,
+		run: func(task Task, ranDependencies []int) TaskExecutionResult {
+			mu.Lock()
+			ranDependenciesOf[task.ID] = ranDependencies
+			mu.Unlock()
+			// No files changed, which leaves the task in progress on the board
+			return TaskExecutionResult{Success: true}
+		},
+		// The stored status of task 1 does not count as finished
+		satisfied: func(taskID int) bool { return false },
+		report:    func(progress TaskRunProgress) {},
+	}
+
+	summary := scheduler.Run(tasks)
+
+	if !reflect.DeepEqual(summary.Succeeded, []int{1, 2}) || len(summary.Skipped) != 0 {
+		t.Errorf("Expected both tasks to run, got %+v", summary)
+	}
+	if !reflect.DeepEqual(ranDependenciesOf[2], []int{1}) {
+		t.Errorf("Expected task 2 to be told that task 1 ran, got %v", ranDependenciesOf[2])
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"specprint/pkg/forge"
+)
+
+// TaskStatus represents where a task is in its lifecycle
+type TaskStatus string
+
+const (
+	TaskStatusTodo       TaskStatus = "todo"        // Not started yet
+	TaskStatusInProgress TaskStatus = "in-progress" // Being worked on, e.g. a session that produced no commit yet
+	TaskStatusRunning    TaskStatus = "running"     // Claude is currently executing the task
+	TaskStatusReview     TaskStatus = "review"      // Changes were pushed and are awaiting review
+	TaskStatusDone       TaskStatus = "done"        // Accepted by a human
+	TaskStatusMerged     TaskStatus = "merged"      // The task branch was merged
+	TaskStatusFailed     TaskStatus = "failed"      // The last run failed, see LastError
+	TaskStatusCancelled  TaskStatus = "cancelled"   // The last run was cancelled and its uncommitted changes discarded
+)
+
+// taskTransitions lists the statuses each status may move to
+var taskTransitions = map[TaskStatus][]TaskStatus{
+	TaskStatusTodo:       {TaskStatusInProgress, TaskStatusRunning, TaskStatusDone},
+	TaskStatusInProgress: {TaskStatusTodo, TaskStatusRunning, TaskStatusReview, TaskStatusDone},
+	TaskStatusRunning:    {TaskStatusInProgress, TaskStatusReview, TaskStatusFailed, TaskStatusCancelled},
+	TaskStatusReview:     {TaskStatusTodo, TaskStatusInProgress, TaskStatusRunning, TaskStatusDone, TaskStatusMerged},
+	TaskStatusDone:       {TaskStatusTodo, TaskStatusInProgress, TaskStatusRunning, TaskStatusMerged},
+	TaskStatusMerged:     {TaskStatusDone},
+	TaskStatusFailed:     {TaskStatusTodo, TaskStatusInProgress, TaskStatusRunning},
+	TaskStatusCancelled:  {TaskStatusTodo, TaskStatusInProgress, TaskStatusRunning},
+}
+
+// isValidTaskStatus checks if a status is part of the task lifecycle
+func isValidTaskStatus(status TaskStatus) bool {
+	_, ok := taskTransitions[status]
+	return ok
+}
+
+// validateTaskTransition returns an error if a task may not move from one status to another
+func validateTaskTransition(from, to TaskStatus) error {
+	if !isValidTaskStatus(to) {
+		return fmt.Errorf("invalid task status: %s", to)
+	}
+	if from == "" {
+		from = TaskStatusTodo
+	}
+	if from == TaskStatusRunning && to == TaskStatusRunning {
+		return fmt.Errorf("task is already running")
+	}
+	if from == to {
+		return nil
+	}
+	for _, allowed := range taskTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot move task from '%s' to '%s'", from, to)
+}
+
+// transitionTask validates and applies a status change to a stored task. The optional
+// mutate function runs in the same store update, so related fields change atomically.
+func (a *App) transitionTask(workspaceName string, taskID int, to TaskStatus, mutate func(task *Task)) (Task, error) {
+	var updated Task
+	_, err := a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		index := board.findTask(taskID)
+		if index < 0 {
+			return fmt.Errorf("task %d not found", taskID)
+		}
+
+		task := &board.Tasks[index]
+		if err := validateTaskTransition(task.Status, to); err != nil {
+			return fmt.Errorf("task %d: %v", taskID, err)
+		}
+
+		task.Status = to
+		if mutate != nil {
+			mutate(task)
+		}
+		updated = *task
+		return nil
+	})
+	return updated, err
+}
+
+// startTaskRun marks a task as running before Claude is started on it. Tasks that are
+// not on the board yet (e.g. created by an older frontend) are added first.
+func (a *App) startTaskRun(workspaceName string, taskID int, taskTitle, taskDescription, branchName, worktreePath string) error {
+	_, err := a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		index := board.findTask(taskID)
+		if index < 0 {
+			task := Task{ID: taskID, Title: taskTitle, Description: taskDescription}
+			normalizeTask(&task)
+			board.Tasks = append(board.Tasks, task)
+			index = len(board.Tasks) - 1
+		}
+
+		task := &board.Tasks[index]
+		if err := validateTaskTransition(task.Status, TaskStatusRunning); err != nil {
+			return fmt.Errorf("cannot run task %d: %v", taskID, err)
+		}
+
+		now := time.Now()
+		task.Status = TaskStatusRunning
+		task.LastRunAt = &now
+		task.LastError = ""
+		if branchName != "" {
+			task.BranchName = branchName
+		}
+		if worktreePath != "" {
+			task.WorktreePath = worktreePath
+		}
+		return nil
+	})
+	return err
+}
+
+// finishTaskRun records the outcome of a run: failed runs keep the error, cancelled runs are
+// marked as such, runs that pushed a commit await review, and runs without changes stay in progress
+// unless the task's pull request is still open for review.
+func (a *App) finishTaskRun(workspaceName string, taskID int, result TaskExecutionResult) {
+	status := TaskStatusInProgress
+	if result.Cancelled {
+		status = TaskStatusCancelled
+	} else if !result.Success {
+		status = TaskStatusFailed
+	} else if len(result.FilesChanged) > 0 || a.hasOpenPullRequest(workspaceName, taskID) {
+		// e.g. Claude answered review feedback without changing the code
+		status = TaskStatusReview
+	}
+
+	_, err := a.transitionTask(workspaceName, taskID, status, func(task *Task) {
+		if result.Success || result.Cancelled {
+			task.LastError = ""
+		} else {
+			task.LastError = result.Message
+		}
+		if result.BranchName != "" {
+			task.BranchName = result.BranchName
+		}
+		if result.SessionID != "" {
+			task.SessionID = result.SessionID
+		}
+		if result.WorktreePath != "" {
+			task.WorktreePath = result.WorktreePath
+		}
+		if result.RunConfig != nil {
+			task.LastRunConfig = result.RunConfig
+		}
+		if result.Usage != nil {
+			task.LastSummary = result.ClaudeSummary
+			task.LastUsage = result.Usage
+		}
+		task.LastVerification = result.Verification
+	})
+	if err != nil {
+		fmt.Printf("Warning: Failed to record result of task %d: %v\n", taskID, err)
+	}
+}
+
+// hasOpenPullRequest checks if a stored task has an open pull request
+func (a *App) hasOpenPullRequest(workspaceName string, taskID int) bool {
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return false
+	}
+	index := board.findTask(taskID)
+	return index >= 0 && board.Tasks[index].PullRequest != nil && board.Tasks[index].PullRequest.State == forge.StateOpen
+}
+
+// parseWorktreeDirectory extracts the task ID and workspace name from a worktree
+// directory named task-{number}-{workspacename}
+func parseWorktreeDirectory(worktreePath string) (int, string, bool) {
+	parts := strings.SplitN(filepath.Base(filepath.Clean(worktreePath)), "-", 3)
+	if len(parts) < 3 || parts[0] != "task" || parts[2] == "" {
+		return 0, "", false
+	}
+
+	taskID, err := strconv.Atoi(parts[1])
+	if err != nil || taskID <= 0 {
+		return 0, "", false
+	}
+
+	return taskID, parts[2], true
+}
+
+// parseKnownWorktreeDirectory is parseWorktreeDirectory for worktrees of existing workspaces.
+// Worktree paths come from the frontend, so the workspace name is checked before anything is
+// stored under it.
+func (a *App) parseKnownWorktreeDirectory(worktreePath string) (int, string, bool) {
+	taskID, workspaceName, ok := parseWorktreeDirectory(worktreePath)
+	if !ok {
+		return 0, "", false
+	}
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return 0, "", false
+	}
+
+	return taskID, workspaceName, true
+}
+
+// findTaskForWorktree returns the workspace and ID of the stored task that owns a worktree
+func (a *App) findTaskForWorktree(worktreePath string) (string, int, bool) {
+	taskID, workspaceName, ok := a.parseKnownWorktreeDirectory(worktreePath)
+	if !ok {
+		return "", 0, false
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil || board.findTask(taskID) < 0 {
+		return "", 0, false
+	}
+
+	return workspaceName, taskID, true
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestValidateTaskTransition(t *testing.T) {
+	testCases := []struct {
+		from    TaskStatus
+		to      TaskStatus
+		allowed bool
+	}{
+		{TaskStatusTodo, TaskStatusRunning, true},
+		{"", TaskStatusRunning, true},
+		{TaskStatusRunning, TaskStatusReview, true},
+		{TaskStatusRunning, TaskStatusFailed, true},
+		{TaskStatusRunning, TaskStatusCancelled, true},
+		{TaskStatusTodo, TaskStatusCancelled, false},
+		{TaskStatusRunning, TaskStatusRunning, false},
+		{TaskStatusRunning, TaskStatusDone, false},
+		{TaskStatusMerged, TaskStatusRunning, false},
+		{TaskStatusFailed, TaskStatusRunning, true},
+		{TaskStatusDone, TaskStatusDone, true},
+		{TaskStatusTodo, "archived", false},
+	}
+
+	for _, tc := range testCases {
+		err := validateTaskTransition(tc.from, tc.to)
+		if tc.allowed && err != nil {
+			t.Errorf("Expected %s -> %s to be allowed, got: %v", tc.from, tc.to, err)
+		}
+		if !tc.allowed && err == nil {
+			t.Errorf("Expected %s -> %s to be rejected, got no error", tc.from, tc.to)
+		}
+	}
+}
+
+func TestStartTaskRunRejectsRunningTask(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	// Tasks that are not on the board yet are added when they are started
+	if err := app.startTaskRun("demo", 3, "Add search", "Full-text search", "task-3-add-search", "/tmp/task-3-demo"); err != nil {
+		t.Fatalf("Expected task to start, got: %v", err)
+	}
+
+	if err := app.startTaskRun("demo", 3, "Add search", "Full-text search", "", ""); err == nil {
+		t.Error("Expected starting a running task to fail, got no error")
+	}
+
+	app.finishTaskRun("demo", 3, TaskExecutionResult{Success: false, Message: "Claude Code execution failed"})
+
+	list := app.ListTasks("demo")
+	if len(list.Tasks) != 1 {
+		t.Fatalf("Expected 1 task, got %d", len(list.Tasks))
+	}
+	task := list.Tasks[0]
+	if task.Status != TaskStatusFailed || task.LastError == "" {
+		t.Errorf("Expected failed task with an error, got status %s and error %q", task.Status, task.LastError)
+	}
+	if task.BranchName != "task-3-add-search" || task.LastRunAt == nil {
+		t.Errorf("Expected branch and run time to be recorded, got %+v", task)
+	}
+
+	if err := app.startTaskRun("demo", 3, "Add search", "", "", ""); err != nil {
+		t.Errorf("Expected failed task to be restartable, got: %v", err)
+	}
+	app.finishTaskRun("demo", 3, TaskExecutionResult{Success: true, FilesChanged: []string{"search.go"}, SessionID: "abc"})
+
+	list = app.ListTasks("demo")
+	if list.Tasks[0].Status != TaskStatusReview || list.Tasks[0].SessionID != "abc" {
+		t.Errorf("Expected task awaiting review with session abc, got status %s and session %q", list.Tasks[0].Status, list.Tasks[0].SessionID)
+	}
+}
+
+func TestParseWorktreeDirectory(t *testing.T) {
+	taskID, workspaceName, ok := parseWorktreeDirectory("/home/me/.aicodingtool/repos/task-42-frontend-app")
+	if !ok || taskID != 42 || workspaceName != "frontend-app" {
+		t.Errorf("Expected task 42 of frontend-app, got %d %q %v", taskID, workspaceName, ok)
+	}
+
+	if _, _, ok := parseWorktreeDirectory("/home/me/.aicodingtool/repos/myproject"); ok {
+		t.Error("Expected non-worktree directory to be rejected")
+	}
+}
+
+func TestParseKnownWorktreeDirectory(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	if taskID, workspaceName, ok := app.parseKnownWorktreeDirectory("/tmp/task-3-demo"); !ok || taskID != 3 || workspaceName != "demo" {
+		t.Errorf("Expected task 3 of demo, got %d %q %v", taskID, workspaceName, ok)
+	}
+	for _, path := range []string{"/tmp/task-3-missing", "/tmp/task-3-.."} {
+		if _, _, ok := app.parseKnownWorktreeDirectory(path); ok {
+			t.Errorf("Expected %s to be rejected as it names no workspace", path)
+		}
+	}
+}
@@ -0,0 +1,537 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TaskBoard represents the persisted task list of a workspace
+type TaskBoard struct {
+	Workspace        string    `json:"workspace"`
+	Tasks            []Task    `json:"tasks"`
+	LastUpdated      time.Time `json:"lastUpdated"`
+	SourcePRD        string    `json:"sourcePrd,omitempty"`        // The PRD the tasks were generated from, for incremental regeneration
+	SourcePRDVersion int       `json:"sourcePrdVersion,omitempty"` // The version of SourcePRD in the workspace's PRD history
+
+	SpecSources map[string]SpecSource `json:"specSources,omitempty"` // Like SourcePRD, for the tasks of named specs
+}
+
+// TaskListResult represents the result of listing or replacing the tasks of a workspace
+type TaskListResult struct {
+	Success     bool             `json:"success"`
+	Message     string           `json:"message"`
+	Tasks       []Task           `json:"tasks,omitempty"`
+	LastUpdated time.Time        `json:"lastUpdated,omitempty"`
+	Diagnostics []TaskDiagnostic `json:"diagnostics,omitempty"` // Problems found in the dependency graph
+}
+
+// TaskResult represents the result of an operation on a single task
+type TaskResult struct {
+	Success     bool             `json:"success"`
+	Message     string           `json:"message"`
+	Task        *Task            `json:"task,omitempty"`
+	Diagnostics []TaskDiagnostic `json:"diagnostics,omitempty"` // Problems the change would cause in the dependency graph
+}
+
+// taskStore persists task boards as JSON files under ~/.aicodingtool/tasks
+type taskStore struct {
+	mu sync.Mutex
+}
+
+// boardPath returns the path of the task board file for a workspace
+func (s *taskStore) boardPath(workspaceName string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".aicodingtool", "tasks", workspaceName+".json"), nil
+}
+
+// load reads a task board from disk; the caller must hold s.mu
+func (s *taskStore) load(workspaceName string) (*TaskBoard, error) {
+	boardFile, err := s.boardPath(workspaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &TaskBoard{Workspace: workspaceName, Tasks: []Task{}}
+	data, err := os.ReadFile(boardFile)
+	if os.IsNotExist(err) {
+		return board, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, board); err != nil {
+		return nil, fmt.Errorf("failed to parse task board %s: %v", boardFile, err)
+	}
+	if board.Tasks == nil {
+		board.Tasks = []Task{}
+	}
+	return board, nil
+}
+
+// save writes a task board to disk; the caller must hold s.mu
+func (s *taskStore) save(board *TaskBoard) error {
+	boardFile, err := s.boardPath(board.Workspace)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(boardFile), 0755); err != nil {
+		return err
+	}
+
+	board.LastUpdated = time.Now()
+	data, err := json.MarshalIndent(board, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated board behind
+	tmpFile := boardFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, boardFile)
+}
+
+// Load returns a copy of the task board of a workspace
+func (s *taskStore) Load(workspaceName string) (TaskBoard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	board, err := s.load(workspaceName)
+	if err != nil {
+		return TaskBoard{}, err
+	}
+	return *board, nil
+}
+
+// Update loads the task board of a workspace, applies fn and saves the result.
+// Nothing is written if fn returns an error.
+func (s *taskStore) Update(workspaceName string, fn func(board *TaskBoard) error) (TaskBoard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	board, err := s.load(workspaceName)
+	if err != nil {
+		return TaskBoard{}, err
+	}
+	if err := fn(board); err != nil {
+		return TaskBoard{}, err
+	}
+	if err := s.save(board); err != nil {
+		return TaskBoard{}, err
+	}
+	return *board, nil
+}
+
+// Delete removes the task board of a workspace
+func (s *taskStore) Delete(workspaceName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	boardFile, err := s.boardPath(workspaceName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(boardFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// findTask returns the index of the task with the given ID, or -1 if it is not on the board
+func (b *TaskBoard) findTask(taskID int) int {
+	for i := range b.Tasks {
+		if b.Tasks[i].ID == taskID {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextTaskID returns the next free task ID on the board
+func (b *TaskBoard) nextTaskID() int {
+	maxID := 0
+	for _, task := range b.Tasks {
+		if task.ID > maxID {
+			maxID = task.ID
+		}
+	}
+	return maxID + 1
+}
+
+// normalizeTask fills in defaults for fields the frontend or the model may leave empty
+func normalizeTask(task *Task) {
+	task.Title = strings.TrimSpace(task.Title)
+	if task.Dependencies == nil {
+		task.Dependencies = []int{}
+	}
+	if task.Status == "" {
+		task.Status = TaskStatusTodo
+	}
+}
+
+// normalizeTaskList checks that a task list has unique positive IDs and normalizes its tasks
+func normalizeTaskList(tasks []Task) error {
+	seen := make(map[int]bool)
+	for i := range tasks {
+		if tasks[i].ID <= 0 {
+			return fmt.Errorf("Task %d has invalid ID: %d", i+1, tasks[i].ID)
+		}
+		if seen[tasks[i].ID] {
+			return fmt.Errorf("Duplicate task ID: %d", tasks[i].ID)
+		}
+		seen[tasks[i].ID] = true
+		normalizeTask(&tasks[i])
+	}
+	return nil
+}
+
+// checkRunningTasksKept checks that replacing a task list leaves the status of running tasks
+// alone and puts no other task into 'running'; only runs change those statuses
+func checkRunningTasksKept(current, replacement []Task) error {
+	statuses := make(map[int]TaskStatus, len(replacement))
+	for _, task := range replacement {
+		statuses[task.ID] = task.Status
+	}
+	for _, task := range current {
+		if task.Status != TaskStatusRunning {
+			continue
+		}
+		status, ok := statuses[task.ID]
+		if !ok {
+			return fmt.Errorf("task %d is running and cannot be removed", task.ID)
+		}
+		if status != TaskStatusRunning {
+			return fmt.Errorf("task %d is running; its status changes when the run ends or is cancelled", task.ID)
+		}
+		delete(statuses, task.ID)
+	}
+	for id, status := range statuses {
+		if status == TaskStatusRunning {
+			return fmt.Errorf("task %d can only be moved to 'running' by starting it", id)
+		}
+	}
+	return nil
+}
+
+// taskDiagnostics returns the diagnostics that involve a task
+func taskDiagnostics(diagnostics []TaskDiagnostic, taskID int) []TaskDiagnostic {
+	var involved []TaskDiagnostic
+	for _, diagnostic := range diagnostics {
+		if diagnostic.TaskID == taskID || (diagnostic.Kind == DiagnosticCycle && containsTaskID(diagnostic.Related, taskID)) {
+			involved = append(involved, diagnostic)
+		}
+	}
+	return involved
+}
+
+// findWorkspace looks up a workspace by name without changing workspaces.json
+func (a *App) findWorkspace(workspaceName string) (*Workspace, error) {
+	if strings.TrimSpace(workspaceName) == "" {
+		return nil, errors.New("Workspace name cannot be empty")
+	}
+
+	// Look up without GetWorkspaces, which rewrites workspaces.json
+	workspaces, err := a.listWorkspaces()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list workspaces: %v", err)
+	}
+
+	for i := range workspaces {
+		if workspaces[i].Name == workspaceName {
+			return &workspaces[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("Workspace '%s' not found", workspaceName)
+}
+
+// ListTasks returns all tasks stored for a workspace in board order
+func (a *App) ListTasks(workspaceName string) TaskListResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return TaskListResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	board, err := a.tasks.Load(workspaceName)
+	if err != nil {
+		return TaskListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to load tasks: %v", err),
+		}
+	}
+
+	return TaskListResult{
+		Success:     true,
+		Message:     fmt.Sprintf("Found %d tasks for workspace '%s'", len(board.Tasks), workspaceName),
+		Tasks:       board.Tasks,
+		LastUpdated: board.LastUpdated,
+	}
+}
+
+// SaveTasks replaces the whole task list of a workspace, e.g. when importing a board kept by the frontend
+func (a *App) SaveTasks(workspaceName string, tasks []Task) TaskListResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return TaskListResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	if err := normalizeTaskList(tasks); err != nil {
+		return TaskListResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	// An invalid graph would keep RunWorkspaceTasks from running any task of the board
+	if diagnostics := validateTaskGraph(tasks); len(diagnostics) > 0 {
+		return TaskListResult{
+			Success:     false,
+			Message:     fmt.Sprintf("Tasks have an invalid dependency graph:\n%s", formatDiagnostics(diagnostics)),
+			Diagnostics: diagnostics,
+		}
+	}
+
+	board, err := a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		if err := checkRunningTasksKept(board.Tasks, tasks); err != nil {
+			return err
+		}
+		board.Tasks = tasks
+		return nil
+	})
+	if err != nil {
+		return TaskListResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save tasks: %v", err),
+		}
+	}
+
+	return TaskListResult{
+		Success:     true,
+		Message:     fmt.Sprintf("Saved %d tasks for workspace '%s'", len(board.Tasks), workspaceName),
+		Tasks:       board.Tasks,
+		LastUpdated: board.LastUpdated,
+	}
+}
+
+// CreateTask adds a new task to a workspace, assigning the next free ID
+func (a *App) CreateTask(workspaceName string, task Task) TaskResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return TaskResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	if strings.TrimSpace(task.Title) == "" {
+		return TaskResult{
+			Success: false,
+			Message: "Task title cannot be empty",
+		}
+	}
+
+	if task.Status != "" && !isValidTaskStatus(task.Status) {
+		return TaskResult{
+			Success: false,
+			Message: fmt.Sprintf("Invalid task status: %s", task.Status),
+		}
+	}
+
+	// Like MoveTask, only runs put tasks into these statuses
+	if task.Status == TaskStatusRunning {
+		return TaskResult{
+			Success: false,
+			Message: "Tasks can only be moved to 'running' by starting them",
+		}
+	}
+
+	if task.Status == TaskStatusCancelled {
+		return TaskResult{
+			Success: false,
+			Message: "Tasks can only be moved to 'cancelled' by cancelling them",
+		}
+	}
+
+	normalizeTask(&task)
+	var diagnostics []TaskDiagnostic
+	_, err := a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		task.ID = board.nextTaskID()
+		board.Tasks = append(board.Tasks, task)
+		if diagnostics = taskDiagnostics(validateTaskGraph(board.Tasks), task.ID); len(diagnostics) > 0 {
+			return fmt.Errorf("invalid dependencies:\n%s", formatDiagnostics(diagnostics))
+		}
+		return nil
+	})
+	if err != nil {
+		return TaskResult{
+			Success:     false,
+			Message:     fmt.Sprintf("Failed to create task: %v", err),
+			Diagnostics: diagnostics,
+		}
+	}
+
+	return TaskResult{
+		Success: true,
+		Message: fmt.Sprintf("Created task %d", task.ID),
+		Task:    &task,
+	}
+}
+
+// UpdateTask replaces the editable fields of an existing task. Use MoveTask to change its column.
+func (a *App) UpdateTask(workspaceName string, task Task) TaskResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return TaskResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	if strings.TrimSpace(task.Title) == "" {
+		return TaskResult{
+			Success: false,
+			Message: "Task title cannot be empty",
+		}
+	}
+
+	if task.RunConfig != nil {
+		if err := task.RunConfig.Validate(); err != nil {
+			return TaskResult{
+				Success: false,
+				Message: fmt.Sprintf("Invalid Claude run config: %v", err),
+			}
+		}
+	}
+
+	var updated Task
+	var diagnostics []TaskDiagnostic
+	_, err := a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		index := board.findTask(task.ID)
+		if index < 0 {
+			return fmt.Errorf("task %d not found", task.ID)
+		}
+
+		existing := &board.Tasks[index]
+		existing.Title = strings.TrimSpace(task.Title)
+		existing.Description = task.Description
+		existing.Dependencies = task.Dependencies
+		existing.Priority = task.Priority
+		existing.Estimate = task.Estimate
+		existing.AcceptanceCriteria = task.AcceptanceCriteria
+		existing.Files = task.Files
+		existing.TestPlan = task.TestPlan
+		existing.RunConfig = task.RunConfig
+		normalizeTask(existing)
+
+		// Problems elsewhere on the board don't keep this task from being edited
+		if diagnostics = taskDiagnostics(validateTaskGraph(board.Tasks), task.ID); len(diagnostics) > 0 {
+			return fmt.Errorf("invalid dependencies:\n%s", formatDiagnostics(diagnostics))
+		}
+
+		updated = *existing
+		return nil
+	})
+	if err != nil {
+		return TaskResult{
+			Success:     false,
+			Message:     fmt.Sprintf("Failed to update task %d: %v", task.ID, err),
+			Diagnostics: diagnostics,
+		}
+	}
+
+	return TaskResult{
+		Success: true,
+		Message: fmt.Sprintf("Updated task %d", task.ID),
+		Task:    &updated,
+	}
+}
+
+// MoveTask moves a task to another status column. A non-negative position places the task
+// before the task currently at that position within the column; otherwise it is appended.
+func (a *App) MoveTask(workspaceName string, taskID int, status TaskStatus, position int) TaskResult {
+	if _, err := a.findWorkspace(workspaceName); err != nil {
+		return TaskResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	if !isValidTaskStatus(status) {
+		return TaskResult{
+			Success: false,
+			Message: fmt.Sprintf("Invalid task status: %s", status),
+		}
+	}
+
+	if status == TaskStatusRunning {
+		return TaskResult{
+			Success: false,
+			Message: "Tasks can only be moved to 'running' by starting them",
+		}
+	}
+
+	if status == TaskStatusCancelled {
+		return TaskResult{
+			Success: false,
+			Message: "Tasks can only be moved to 'cancelled' by cancelling them",
+		}
+	}
+
+	var moved Task
+	_, err := a.tasks.Update(workspaceName, func(board *TaskBoard) error {
+		index := board.findTask(taskID)
+		if index < 0 {
+			return fmt.Errorf("task %d not found", taskID)
+		}
+
+		moved = board.Tasks[index]
+		if err := validateTaskTransition(moved.Status, status); err != nil {
+			return err
+		}
+		moved.Status = status
+		board.Tasks = append(board.Tasks[:index], board.Tasks[index+1:]...)
+
+		// Find the slice index of the task currently at the requested column position
+		insertAt := len(board.Tasks)
+		columnIndex := 0
+		for i, task := range board.Tasks {
+			if task.Status != status {
+				continue
+			}
+			if position >= 0 && columnIndex == position {
+				insertAt = i
+				break
+			}
+			columnIndex++
+		}
+
+		board.Tasks = append(board.Tasks, Task{})
+		copy(board.Tasks[insertAt+1:], board.Tasks[insertAt:])
+		board.Tasks[insertAt] = moved
+		return nil
+	})
+	if err != nil {
+		return TaskResult{
+			Success: false,
+			Message: fmt.Sprintf("Failed to move task %d: %v", taskID, err),
+		}
+	}
+
+	return TaskResult{
+		Success: true,
+		Message: fmt.Sprintf("Moved task %d to '%s'", taskID, status),
+		Task:    &moved,
+	}
+}
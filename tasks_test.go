@@ -0,0 +1,181 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupTestWorkspace points the home directory at a temporary location and creates an empty workspace in it
+func setupTestWorkspace(t *testing.T, workspaceName string) *App {
+	t.Helper()
+
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+
+	if err := os.MkdirAll(filepath.Join(homeDir, ".aicodingtool", "repos", workspaceName), 0755); err != nil {
+		t.Fatalf("Failed to create workspace directory: %v", err)
+	}
+
+	return NewApp()
+}
+
+func TestTaskStoreCreateListMove(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	first := app.CreateTask("demo", Task{Title: "Set up CI", Priority: "high", Estimate: "2h"})
+	if !first.Success {
+		t.Fatalf("Expected success creating task, got: %s", first.Message)
+	}
+	if first.Task.ID != 1 || first.Task.Status != TaskStatusTodo {
+		t.Errorf("Expected task 1 in todo, got task %d in %s", first.Task.ID, first.Task.Status)
+	}
+
+	second := app.CreateTask("demo", Task{Title: "Add login", Dependencies: []int{1}})
+	if !second.Success || second.Task.ID != 2 {
+		t.Fatalf("Expected task 2 to be created, got: %+v", second)
+	}
+
+	moved := app.MoveTask("demo", 2, TaskStatusInProgress, -1)
+	if !moved.Success {
+		t.Fatalf("Expected success moving task, got: %s", moved.Message)
+	}
+
+	// Reload through a fresh App to make sure the state was persisted
+	list := NewApp().ListTasks("demo")
+	if !list.Success {
+		t.Fatalf("Expected success listing tasks, got: %s", list.Message)
+	}
+	if len(list.Tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(list.Tasks))
+	}
+	if list.Tasks[1].Status != TaskStatusInProgress {
+		t.Errorf("Expected task 2 to be in-progress, got %s", list.Tasks[1].Status)
+	}
+
+	// Moving to position 0 of the todo column places the task first
+	app.MoveTask("demo", 2, TaskStatusTodo, 0)
+	list = app.ListTasks("demo")
+	if list.Tasks[0].ID != 2 {
+		t.Errorf("Expected task 2 to be first after moving to position 0, got task %d", list.Tasks[0].ID)
+	}
+
+	if result := app.MoveTask("demo", 2, "archived", -1); result.Success {
+		t.Error("Expected failure moving task to an unknown column, got success")
+	}
+}
+
+func TestCreateTaskRejectsRunStatuses(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	for _, status := range []TaskStatus{TaskStatusRunning, TaskStatusCancelled} {
+		if result := app.CreateTask("demo", Task{Title: "Add search", Status: status}); result.Success {
+			t.Errorf("Expected creating a task as '%s' to be rejected", status)
+		}
+	}
+	if result := app.CreateTask("demo", Task{Title: "Add search", Status: TaskStatusReview}); !result.Success {
+		t.Errorf("Expected creating a task in review to succeed, got: %s", result.Message)
+	}
+}
+
+func TestTaskStoreUpdateAndDelete(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	app.SaveTasks("demo", []Task{
+		{ID: 1, Title: "Design schema"},
+		{ID: 2, Title: "Build API", Dependencies: []int{1}},
+	})
+
+	updated := app.UpdateTask("demo", Task{ID: 2, Title: "Build REST API", Dependencies: []int{1}, Priority: "high"})
+	if !updated.Success || updated.Task.Title != "Build REST API" {
+		t.Fatalf("Expected task 2 to be renamed, got: %+v", updated)
+	}
+
+	if result := app.UpdateTask("demo", Task{ID: 42, Title: "Missing"}); result.Success {
+		t.Error("Expected failure updating a non-existent task, got success")
+	}
+
+	if result := app.DeleteTask("demo", 1); !result.Success {
+		t.Fatalf("Expected success deleting task, got: %s", result.Message)
+	}
+
+	list := app.ListTasks("demo")
+	if len(list.Tasks) != 1 || list.Tasks[0].ID != 2 {
+		t.Fatalf("Expected only task 2 to remain, got: %+v", list.Tasks)
+	}
+	if len(list.Tasks[0].Dependencies) != 0 {
+		t.Errorf("Expected dependency on deleted task to be dropped, got: %v", list.Tasks[0].Dependencies)
+	}
+}
+
+func TestSaveTasksRejectsDuplicateIDs(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	result := app.SaveTasks("demo", []Task{{ID: 1, Title: "A"}, {ID: 1, Title: "B"}})
+	if result.Success {
+		t.Error("Expected failure for duplicate task IDs, got success")
+	}
+}
+
+func TestListTasksUnknownWorkspace(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	result := app.ListTasks("missing")
+	if result.Success {
+		t.Error("Expected failure for non-existent workspace, got success")
+	}
+}
+
+func TestTaskBindingsDoNotRewriteWorkspaces(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	if result := app.ListTasks("demo"); !result.Success {
+		t.Fatalf("Expected success listing tasks, got: %s", result.Message)
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	if _, err := os.Stat(filepath.Join(homeDir, ".aicodingtool", "workspaces.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected looking up the workspace to leave workspaces.json alone, got: %v", err)
+	}
+}
+
+func TestTaskChangesKeepTheGraphValid(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	app.SaveTasks("demo", []Task{
+		{ID: 1, Title: "Design schema"},
+		{ID: 2, Title: "Build API", Dependencies: []int{1}},
+	})
+
+	if result := app.SaveTasks("demo", []Task{{ID: 1, Title: "A", Dependencies: []int{2}}, {ID: 2, Title: "B", Dependencies: []int{1}}}); result.Success || len(result.Diagnostics) == 0 {
+		t.Errorf("Expected a cycle to be rejected with diagnostics, got %+v", result)
+	}
+	if result := app.UpdateTask("demo", Task{ID: 1, Title: "Design schema", Dependencies: []int{2}}); result.Success || len(result.Diagnostics) != 1 || result.Diagnostics[0].Kind != DiagnosticCycle {
+		t.Errorf("Expected an update closing a cycle to be rejected, got %+v", result)
+	}
+	if result := app.UpdateTask("demo", Task{ID: 2, Title: "Build API", Dependencies: []int{2, 7}}); result.Success || len(result.Diagnostics) != 2 {
+		t.Errorf("Expected self and missing dependencies to be rejected, got %+v", result)
+	}
+	if result := app.CreateTask("demo", Task{Title: "Build UI", Dependencies: []int{9}}); result.Success {
+		t.Error("Expected a new task depending on a missing task to be rejected")
+	}
+
+	// Running tasks keep their status until their run ends
+	if err := app.startTaskRun("demo", 1, "Design schema", "", "", ""); err != nil {
+		t.Fatalf("Expected task to start, got: %v", err)
+	}
+	if result := app.SaveTasks("demo", []Task{{ID: 1, Title: "Design schema", Status: TaskStatusDone}, {ID: 2, Title: "Build API"}}); result.Success {
+		t.Error("Expected changing the status of a running task to be rejected")
+	}
+	if result := app.SaveTasks("demo", []Task{{ID: 1, Title: "Design schema", Status: TaskStatusRunning}, {ID: 2, Title: "Build API", Status: TaskStatusRunning}}); result.Success {
+		t.Error("Expected saving a task as running to be rejected")
+	}
+	if result := app.SaveTasks("demo", []Task{{ID: 1, Title: "Design schema v2", Status: TaskStatusRunning}, {ID: 2, Title: "Build API"}}); !result.Success {
+		t.Errorf("Expected saving the running task unchanged to succeed, got: %s", result.Message)
+	}
+
+	board, _ := app.tasks.Load("demo")
+	if board.Tasks[0].Status != TaskStatusRunning || len(board.Tasks[0].Dependencies) != 0 {
+		t.Errorf("Expected the rejected changes to leave the board alone, got %+v", board.Tasks[0])
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// What happens to a task's changes when verification fails
+const (
+	VerificationBlock = "block" // Leave the changes uncommitted in the worktree (the default)
+	VerificationMark  = "mark"  // Commit and push the changes, but mark the task as failed
+)
+
+// defaultVerificationTimeout bounds a verification step that does not set its own timeout
+const defaultVerificationTimeout = 10 * time.Minute
+
+// maxVerificationOutput bounds the output kept of each verification step; the end is kept,
+// since that is where compilers and test runners report failures
+const maxVerificationOutput = 8000
+
+// VerificationStep is a command run in the task worktree after Claude finishes, such as a build,
+// test or lint command
+type VerificationStep struct {
+	Name           string `json:"name"`
+	Command        string `json:"command"`                  // Run by the shell in the worktree
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty"` // 0 uses the default of 10 minutes
+}
+
+// VerificationConfig configures the checks run on a task's changes before they are committed
+type VerificationConfig struct {
+	Steps     []VerificationStep `json:"steps,omitempty"`
+	OnFailure string             `json:"onFailure,omitempty"` // VerificationBlock or VerificationMark; empty blocks
+}
+
+// VerificationStepResult is the outcome of one verification step
+type VerificationStepResult struct {
+	Name       string `json:"name"`
+	Command    string `json:"command"`
+	Passed     bool   `json:"passed"`
+	ExitCode   int    `json:"exitCode"`
+	TimedOut   bool   `json:"timedOut,omitempty"`
+	Output     string `json:"output,omitempty"` // Combined stdout and stderr, cut to the last part
+	DurationMs int    `json:"durationMs"`
+}
+
+// VerificationResult is the outcome of verifying a task's changes. Steps run in order and stop
+// at the first failure.
+type VerificationResult struct {
+	Passed bool                     `json:"passed"`
+	Steps  []VerificationStepResult `json:"steps"`
+}
+
+// Validate checks that every step has a command and that the failure mode is known
+func (c VerificationConfig) Validate() error {
+	for i, step := range c.Steps {
+		if strings.TrimSpace(step.Command) == "" {
+			return fmt.Errorf("verification step %d has no command", i+1)
+		}
+		if step.TimeoutSeconds < 0 {
+			return fmt.Errorf("verification step %d has a negative timeout", i+1)
+		}
+	}
+	switch c.OnFailure {
+	case "", VerificationBlock, VerificationMark:
+		return nil
+	default:
+		return fmt.Errorf("unknown verification failure mode '%s': use '%s' or '%s'", c.OnFailure, VerificationBlock, VerificationMark)
+	}
+}
+
+// blocksCommit checks if failed verification keeps the changes from being committed
+func (c VerificationConfig) blocksCommit() bool {
+	return c.OnFailure != VerificationMark
+}
+
+// failedStep returns the step that failed verification
+func (r *VerificationResult) failedStep() VerificationStepResult {
+	for _, step := range r.Steps {
+		if !step.Passed {
+			return step
+		}
+	}
+	return VerificationStepResult{}
+}
+
+// summary describes a failed verification in one line for task messages
+func (r *VerificationResult) summary() string {
+	step := r.failedStep()
+	if step.TimedOut {
+		return fmt.Sprintf("verification step '%s' timed out", step.Name)
+	}
+	return fmt.Sprintf("verification step '%s' failed with exit code %d", step.Name, step.ExitCode)
+}
+
+// runVerification runs the verification steps in a directory and stops at the first failure.
+// It returns nil if there are no steps.
+func runVerification(ctx context.Context, dir string, config VerificationConfig) *VerificationResult {
+	if len(config.Steps) == 0 {
+		return nil
+	}
+
+	result := &VerificationResult{Passed: true, Steps: []VerificationStepResult{}}
+	for i, step := range config.Steps {
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step %d", i+1)
+		}
+		stepResult := runVerificationStep(ctx, dir, step)
+		result.Steps = append(result.Steps, stepResult)
+		if !stepResult.Passed {
+			result.Passed = false
+			break
+		}
+	}
+	return result
+}
+
+// runVerificationStep runs one verification command with its timeout
+func runVerificationStep(ctx context.Context, dir string, step VerificationStep) VerificationStepResult {
+	timeout := defaultVerificationTimeout
+	if step.TimeoutSeconds > 0 {
+		timeout = time.Duration(step.TimeoutSeconds) * time.Second
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(stepCtx, step.Command)
+	cmd.Dir = dir
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Don't wait for background processes the command may have left holding its output
+	cmd.WaitDelay = time.Second
+
+	startedAt := time.Now()
+	err := cmd.Run()
+	result := VerificationStepResult{
+		Name:       step.Name,
+		Command:    step.Command,
+		Passed:     err == nil,
+		Output:     tailText(output.String(), maxVerificationOutput),
+		DurationMs: int(time.Since(startedAt).Milliseconds()),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.Is(stepCtx.Err(), context.DeadlineExceeded):
+		result.TimedOut = true
+		result.ExitCode = -1
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.ExitCode = -1
+		result.Output += fmt.Sprintf("\n%v", err)
+	}
+	return result
+}
+
+// shellCommand runs a command line with the platform's shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// tailText keeps the last maxChars bytes of text without splitting a character, marking the cut
+func tailText(text string, maxChars int) string {
+	if len(text) <= maxChars {
+		return text
+	}
+	return "[truncated]\n" + strings.ToValidUTF8(text[len(text)-maxChars:], "")
+}
+
+// verifyWorktree runs the verification steps configured for a workspace in a task worktree
+func (a *App) verifyWorktree(ctx context.Context, workspaceName, worktreePath string) (*VerificationResult, VerificationConfig, error) {
+	settings, err := a.settings.LoadWorkspace(workspaceName)
+	if err != nil {
+		return nil, VerificationConfig{}, fmt.Errorf("failed to load workspace settings: %v", err)
+	}
+	return runVerification(ctx, worktreePath, settings.Verification), settings.Verification, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunVerificationStopsAtFirstFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("verification commands use sh")
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.txt"), []byte("ok"), 0644)
+
+	result := runVerification(context.Background(), dir, VerificationConfig{Steps: []VerificationStep{
+		{Name: "build", Command: "cat main.txt"},
+		{Name: "test", Command: "echo 'FAIL: TestLogin' >&2; exit 3"},
+		{Name: "lint", Command: "echo never"},
+	}})
+	if result == nil || result.Passed || len(result.Steps) != 2 {
+		t.Fatalf("Expected verification to stop after the failing test step, got %+v", result)
+	}
+	if !result.Steps[0].Passed || result.Steps[0].Output != "ok" {
+		t.Errorf("Expected the build step to pass in the worktree, got %+v", result.Steps[0])
+	}
+	if step := result.Steps[1]; step.ExitCode != 3 || !strings.Contains(step.Output, "FAIL: TestLogin") {
+		t.Errorf("Expected the test failure with its output, got %+v", step)
+	}
+	if summary := result.summary(); summary != "verification step 'test' failed with exit code 3" {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+
+	timedOut := runVerification(context.Background(), dir, VerificationConfig{Steps: []VerificationStep{{Command: "sleep 5", TimeoutSeconds: 1}}})
+	if timedOut.Passed || !timedOut.Steps[0].TimedOut || timedOut.Steps[0].Name != "step 1" {
+		t.Errorf("Expected the step to time out, got %+v", timedOut)
+	}
+
+	if result := runVerification(context.Background(), dir, VerificationConfig{}); result != nil {
+		t.Errorf("Expected no result without steps, got %+v", result)
+	}
+}
+
+func TestVerificationConfigValidation(t *testing.T) {
+	app := setupTestWorkspace(t, "demo")
+
+	invalid := []VerificationConfig{
+		{Steps: []VerificationStep{{Name: "build", Command: " "}}},
+		{Steps: []VerificationStep{{Command: "make", TimeoutSeconds: -1}}},
+		{OnFailure: "ignore"},
+	}
+	for _, config := range invalid {
+		if result := app.SaveWorkspaceSettings("demo", WorkspaceSettings{Verification: config}); result.Success {
+			t.Errorf("Expected %+v to be rejected", config)
+		}
+	}
+
+	config := VerificationConfig{Steps: []VerificationStep{{Name: "test", Command: "go test ./..."}}, OnFailure: VerificationMark}
+	if result := app.SaveWorkspaceSettings("demo", WorkspaceSettings{Verification: config}); !result.Success {
+		t.Fatalf("Expected the config to be saved, got: %s", result.Message)
+	}
+	if _, saved, err := app.verifyWorktree(context.Background(), "demo", t.TempDir()); err != nil || saved.blocksCommit() {
+		t.Errorf("Expected the saved config to commit on failure, got %+v (%v)", saved, err)
+	}
+}